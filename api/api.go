@@ -1,95 +1,235 @@
 package api
 
 import (
+	"bytes"
 	"context"
-	"fmt"
+	"errors"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"strings"
 )
 
+// Environment variables read by Default, also used by warren.NewClient and config.Load.
 const (
-	apiKeyEnvKey  string = "WARREN_API_KEY"
-	baseURLEnvKey string = "WARREN_API_BASE_URL"
+	// BaseURLEnvKey is the API base URL
+	BaseURLEnvKey string = "WARREN_API_BASE_URL"
+	// APIKeyEnvKey is the API key
+	APIKeyEnvKey string = "WARREN_API_KEY"
+	// DebugEnvKey enables debug logging of all requests to stderr when set to true value
+	DebugEnvKey string = "WARREN_DEBUG"
 )
 
 // Default creates API where both BaseURL and APIKey comes from environment variables.
-var Default *API = New(os.Getenv(baseURLEnvKey), os.Getenv(apiKeyEnvKey))
+// Setting WARREN_DEBUG=true enables debug logging of all requests to stderr.
+var Default *API = newDefault()
+
+func newDefault() *API {
+	a := New(os.Getenv(BaseURLEnvKey), os.Getenv(APIKeyEnvKey))
+	a.Logger = debugLogger()
+	return a
+}
 
 // ClientResponse is a data structured returned by `doRequest()`.
 // To make the client compatible even when the server changed their response format.
 // User of this library is responsible to handle the Body which is a slice of byte.
 type ClientResponse struct {
-	Error error
-	Body  []byte
+	Error      error
+	Body       []byte
+	StatusCode int
+	Header     http.Header
 }
 
 // API used to holds objects that are needed to make a HTTP call.
+// It's safe for concurrent use as long as its fields aren't modified after the first call.
 type API struct {
 	BaseURL    string
 	APIKey     string
 	HTTPClient *http.Client
+
+	// UserAgent is sent as `User-Agent` header when set.
+	UserAgent string
+
+	// RetryPolicy decides whether a failed request should be retried, nil means never retry.
+	RetryPolicy RetryPolicy
+
+	// RateLimiter throttles outgoing requests (including retries), nil means no limit.
+	RateLimiter *RateLimiter
+
+	// IdempotencyHeader is the header carrying RequestConfig.IdempotencyKey, empty when the provider
+	// doesn't support idempotent requests (the default). See IdempotencyKeyHeader.
+	IdempotencyHeader string
+
+	// CircuitBreaker stops sending requests to BaseURL when it keeps failing, nil means no circuit breaker.
+	CircuitBreaker *CircuitBreaker
+
+	// Middlewares wraps every request, registered using Use().
+	Middlewares []Middleware
+
+	// Builtins are the innermost middlewares, DefaultBuiltins() is used when it's nil.
+	// Set this to wrap or replace apikey header injection and status code check.
+	Builtins []Middleware
+
+	// Logger enables debug logging of all requests when set, secrets are always redacted.
+	Logger *slog.Logger
+
+	// Tracing enables OpenTelemetry span for every call when set.
+	Tracing *Tracing
+
+	// StrictDecoding makes Do() reject response with fields unknown to the target type,
+	// useful to notice API changes early.
+	StrictDecoding bool
+
+	// Cache enables caching of GET responses for configured paths, nil means no caching.
+	Cache *Cache
+
+	// DryRun enables dry-run mode when set, mutating requests (POST, PUT, PATCH, DELETE) are added
+	// to the plan and fail with ErrDryRun instead of being sent. Other requests are sent as usual.
+	DryRun *Plan
 }
 
 // FormRequest make a call with form-encoded payload
 func (a *API) FormRequest(ctx context.Context, cfg RequestConfig) ClientResponse {
-	req, err := a.buildRequest(ctx, cfg)
-	if err != nil {
-		return ClientResponse{Error: err}
-	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	return a.doRequest(req)
+	return a.request(ctx, cfg, "application/x-www-form-urlencoded")
 }
 
 // JsonRequest make a call with json-encoded payload
 func (a *API) JSONRequest(ctx context.Context, cfg RequestConfig) ClientResponse {
-	req, err := a.buildRequest(ctx, cfg)
-	if err != nil {
-		return ClientResponse{Error: err}
+	return a.request(ctx, cfg, "application/json")
+}
+
+// request sends the request and reads the whole response body into ClientResponse.Body.
+func (a *API) request(ctx context.Context, cfg RequestConfig, contentType string) ClientResponse {
+	return a.send(ctx, cfg, contentType, io.ReadAll)
+}
+
+// send sends the request through the cache when it's enabled.
+// Body of successful response is consumed by read.
+func (a *API) send(ctx context.Context, cfg RequestConfig, contentType string, read func(io.Reader) ([]byte, error)) ClientResponse {
+	if a.Cache == nil {
+		return a.exchange(ctx, cfg, contentType, nil, read)
+	}
+	if strings.EqualFold(cfg.Method, "GET") {
+		return a.Cache.get(ctx, a, cfg, contentType, read)
 	}
-	req.Header.Set("Content-Type", "application/json")
-	return a.doRequest(req)
+	resp := a.exchange(ctx, cfg, contentType, nil, read)
+	// status code is set as long as the request reached the server, even if it failed
+	if isMutating(cfg.Method) && resp.StatusCode != 0 {
+		a.Cache.Invalidate("/")
+	}
+	return resp
 }
 
-// buildRequest wraps `http.NewRequestWithContext` and set necessary header for authentication.
-func (a *API) buildRequest(ctx context.Context, cfg RequestConfig) (*http.Request, error) {
+// exchange builds and sends the request with additional header, retrying it as long as RetryPolicy allows.
+// Body of successful response is consumed by read.
+func (a *API) exchange(ctx context.Context, cfg RequestConfig, contentType string, header http.Header, read func(io.Reader) ([]byte, error)) ClientResponse {
 	body, err := cfg.body()
 	if err != nil {
-		return nil, err
+		return ClientResponse{Error: err}
+	}
+	if err := a.dryRun(cfg, body); err != nil {
+		return ClientResponse{Error: err}
 	}
-	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(cfg.Method), cfg.url(a.BaseURL), body)
+	// the provider won't apply the same request twice
+	idempotencyKey := a.IdempotencyHeader != "" && cfg.IdempotencyKey != ""
+	if idempotencyKey {
+		cfg.Retryable = true
+	}
+
+	ctx, span := a.Tracing.startSpan(ctx, cfg)
+	var resp ClientResponse
+	attempt := 1
+	defer func() { endSpan(span, attempt, resp.StatusCode, resp.Error) }()
+
+	for ; ; attempt++ {
+		req, err := a.buildRequest(ctx, cfg, body)
+		if err != nil {
+			resp = ClientResponse{Error: err}
+			return resp
+		}
+		req.Header.Set("Content-Type", contentType)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		if idempotencyKey {
+			req.Header.Set(a.IdempotencyHeader, cfg.IdempotencyKey)
+		}
+		if a.RateLimiter != nil {
+			if err := a.RateLimiter.Wait(ctx, a.APIKey, req.Method); err != nil {
+				resp = ClientResponse{Error: err}
+				return resp
+			}
+		}
+		var t ticket
+		if a.CircuitBreaker != nil {
+			if t, err = a.CircuitBreaker.allow(a.BaseURL); err != nil {
+				resp = ClientResponse{Error: err}
+				return resp
+			}
+		}
+		resp = a.doRequest(cfg, req, read)
+		if a.CircuitBreaker != nil {
+			a.CircuitBreaker.done(a.BaseURL, t, resp)
+		}
+		if resp.Error == nil || a.RetryPolicy == nil {
+			return resp
+		}
+		// successful response that couldn't be read (e.g. invalid JSON), the request was applied
+		if resp.StatusCode != 0 && resp.StatusCode < http.StatusBadRequest {
+			return resp
+		}
+		wait, ok := a.RetryPolicy.ShouldRetry(attempt, cfg, resp.Error)
+		if !ok {
+			return resp
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return resp
+		}
+	}
+}
+
+// buildRequest wraps `http.NewRequestWithContext`, authentication header is set by APIKeyAuth middleware.
+func (a *API) buildRequest(ctx context.Context, cfg RequestConfig, body []byte) (*http.Request, error) {
+	// bytes.Reader makes the request body re-readable (GetBody) which is needed for retries and redirects.
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(cfg.Method), cfg.url(a.BaseURL), r)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("apikey", a.APIKey)
+	if a.UserAgent != "" {
+		req.Header.Set("User-Agent", a.UserAgent)
+	}
 	return req, nil
 }
 
-// doRequest doing the actual request
-func (a *API) doRequest(req *http.Request) ClientResponse {
-	res, err := a.HTTPClient.Do(req)
+// doRequest doing the actual request through the middleware chain
+func (a *API) doRequest(cfg RequestConfig, req *http.Request, read func(io.Reader) ([]byte, error)) ClientResponse {
+	r := &Request{Config: cfg, HTTP: req}
+	a.Tracing.inject(req.Context(), r)
+
+	res, err := a.handler()(r)
 	if err != nil {
+		if res != nil {
+			res.Body.Close()
+		}
+		var e *Error
+		if errors.As(err, &e) {
+			return ClientResponse{Body: e.Body, Error: err, StatusCode: e.StatusCode, Header: e.Header}
+		}
 		return ClientResponse{Error: err}
 	}
 	defer res.Body.Close()
 
-	// we'll only accept 2xx and 3xx as success
-	if res.StatusCode >= 400 {
-		b, err := io.ReadAll(res.Body)
-		if err != nil {
-			return ClientResponse{
-				Error: fmt.Errorf("api call failed with status code=%d", res.StatusCode),
-			}
-		}
-		return ClientResponse{
-			Body:  b,
-			Error: fmt.Errorf("api call failed with status code=%d: %s", res.StatusCode, b),
-		}
+	b, err := read(res.Body)
+	if err != nil {
+		err = &responseError{err}
 	}
-	b, err := io.ReadAll(res.Body)
-	return ClientResponse{Body: b, Error: err}
+	return ClientResponse{Body: b, Error: err, StatusCode: res.StatusCode, Header: res.Header}
 }
 
 // New create an instance of API
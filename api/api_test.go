@@ -143,3 +143,14 @@ func TestJSONRequest(t *testing.T) {
 	resp := c.JSONRequest(context.Background(), cfg)
 	assert.Equal(t, []byte("OK"), resp.Body)
 }
+
+func TestUserAgent(t *testing.T) {
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "my-app/1.0", r.Header.Get("User-Agent"))
+	})
+	defer s.Close()
+
+	c.UserAgent = "my-app/1.0"
+	resp := c.JSONRequest(context.Background(), RequestConfig{Method: "GET", Path: "/test"})
+	assert.NoError(t, resp.Error)
+}
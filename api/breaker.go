@@ -0,0 +1,250 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is matched (using errors.Is) by CircuitOpenError
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitOpenError is returned without sending the request while the circuit of the base URL is open
+type CircuitOpenError struct {
+	BaseURL string
+	// Until is when the circuit lets a trial request through
+	Until time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s for %s until %s", ErrCircuitOpen, e.BaseURL, e.Until.Format(time.RFC3339))
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// CircuitState is state of a circuit
+type CircuitState int
+
+const (
+	// CircuitClosed lets all requests through while counting failures
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects all requests with CircuitOpenError until the cool-down passed
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of trial requests through, their outcome closes or re-opens the circuit
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+// CircuitBreaker stops sending requests to a base URL once too many of them failed.
+// Transport errors and 5xx responses count as failures, there's one circuit per base URL.
+type CircuitBreaker struct {
+	// FailureRatio of failed requests within Window that opens the circuit
+	FailureRatio float64
+	// MinRequests within Window before FailureRatio is evaluated
+	MinRequests int
+	// Window is period the requests are counted in closed state
+	Window time.Duration
+	// CoolDown is how long the circuit stays open before letting trial requests through
+	CoolDown time.Duration
+	// HalfOpenRequests is number of concurrent trial requests allowed in half-open state
+	HalfOpenRequests int
+	// OnStateChange is called on every state transition, it must not block.
+	OnStateChange func(baseURL string, from, to CircuitState)
+
+	now      func() time.Time
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// circuit is state of a single base URL
+type circuit struct {
+	state       CircuitState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	trials      int
+	// generation changes on every state transition, so requests finishing after it are told apart
+	generation int
+}
+
+// ticket is given to request allowed by allow, its outcome only counts in the state it was allowed in
+type ticket struct {
+	generation int
+	trial      bool
+}
+
+// setState changes state of the circuit and starts a new generation
+func (c *circuit) setState(state CircuitState, now time.Time) {
+	c.state = state
+	c.generation++
+	switch state {
+	case CircuitOpen:
+		c.openedAt = now
+	case CircuitHalfOpen:
+		c.trials = 0
+	case CircuitClosed:
+		c.windowStart, c.requests, c.failures = now, 0, 0
+	}
+}
+
+// NewCircuitBreaker creates CircuitBreaker with given failure ratio and cool-down,
+// counting at least 10 requests within 1 minute window and allowing a single trial request.
+func NewCircuitBreaker(failureRatio float64, coolDown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		FailureRatio:     failureRatio,
+		MinRequests:      10,
+		Window:           time.Minute,
+		CoolDown:         coolDown,
+		HalfOpenRequests: 1,
+	}
+}
+
+// State returns current state of the circuit of baseURL
+func (b *CircuitBreaker) State(baseURL string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[baseURL]
+	if !ok {
+		return CircuitClosed
+	}
+	if c.state == CircuitOpen && !b.clock().Before(c.openedAt.Add(b.CoolDown)) {
+		return CircuitHalfOpen
+	}
+	return c.state
+}
+
+// clock returns current time, now is only set in tests
+func (b *CircuitBreaker) clock() time.Time {
+	if b.now == nil {
+		return time.Now()
+	}
+	return b.now()
+}
+
+func (b *CircuitBreaker) circuit(baseURL string) *circuit {
+	if b.circuits == nil {
+		b.circuits = map[string]*circuit{}
+	}
+	c, ok := b.circuits[baseURL]
+	if !ok {
+		c = &circuit{windowStart: b.clock()}
+		b.circuits[baseURL] = c
+	}
+	return c
+}
+
+// allow reports whether request to baseURL can be sent, every allowed request must be followed by done
+// with the returned ticket.
+func (b *CircuitBreaker) allow(baseURL string) (ticket, error) {
+	b.mu.Lock()
+	c := b.circuit(baseURL)
+	from := c.state
+
+	if c.state == CircuitOpen {
+		until := c.openedAt.Add(b.CoolDown)
+		if b.clock().Before(until) {
+			b.mu.Unlock()
+			return ticket{}, &CircuitOpenError{BaseURL: baseURL, Until: until}
+		}
+		c.setState(CircuitHalfOpen, b.clock())
+	}
+	if c.state == CircuitHalfOpen {
+		max := b.HalfOpenRequests
+		if max < 1 {
+			max = 1
+		}
+		if c.trials >= max {
+			b.mu.Unlock()
+			return ticket{}, &CircuitOpenError{BaseURL: baseURL, Until: b.clock()}
+		}
+		c.trials++
+	}
+	t := ticket{generation: c.generation, trial: c.state == CircuitHalfOpen}
+	to := c.state
+	b.mu.Unlock()
+
+	b.notify(baseURL, from, to)
+	return t, nil
+}
+
+// done records outcome of the request allowed by allow
+func (b *CircuitBreaker) done(baseURL string, t ticket, resp ClientResponse) {
+	failed := isCircuitFailure(resp)
+
+	b.mu.Lock()
+	c := b.circuit(baseURL)
+	from := c.state
+	now := b.clock()
+
+	switch {
+	case t.generation != c.generation:
+		// allowed before the last state change, e.g. a slow request sent while closed
+		// finishing in half-open state isn't a trial, its outcome is outdated
+	case t.trial:
+		c.trials--
+		if isCancelled(resp) {
+			// the trial tells nothing about the server, let another request try
+			break
+		}
+		if failed {
+			c.setState(CircuitOpen, now)
+		} else {
+			c.setState(CircuitClosed, now)
+		}
+	case c.state == CircuitClosed:
+		if now.Sub(c.windowStart) >= b.Window {
+			c.windowStart, c.requests, c.failures = now, 0, 0
+		}
+		c.requests++
+		if failed {
+			c.failures++
+		}
+		if failed && c.requests >= b.MinRequests && float64(c.failures)/float64(c.requests) >= b.FailureRatio {
+			c.setState(CircuitOpen, now)
+		}
+	}
+	to := c.state
+	b.mu.Unlock()
+
+	b.notify(baseURL, from, to)
+}
+
+func (b *CircuitBreaker) notify(baseURL string, from, to CircuitState) {
+	if from != to && b.OnStateChange != nil {
+		b.OnStateChange(baseURL, from, to)
+	}
+}
+
+// isCircuitFailure reports whether the response indicates the server is unhealthy.
+// Client errors (4xx) and cancellation by the caller are not failures.
+func isCircuitFailure(resp ClientResponse) bool {
+	if resp.Error == nil {
+		return false
+	}
+	if resp.StatusCode != 0 {
+		return resp.StatusCode >= http.StatusInternalServerError
+	}
+	return !isCancelled(resp)
+}
+
+// isCancelled reports whether the request was cancelled by the caller before getting a response
+func isCancelled(resp ClientResponse) bool {
+	return resp.Error != nil && resp.StatusCode == 0 && errors.Is(resp.Error, context.Canceled)
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type transition struct {
+	from, to CircuitState
+}
+
+func newBreakerAPI(t *testing.T, status *atomic.Int32) (*API, *CircuitBreaker, *[]transition, *time.Time) {
+	a, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	})
+	t.Cleanup(s.Close)
+
+	var mu sync.Mutex
+	transitions := &[]transition{}
+	now := time.Now()
+	b := NewCircuitBreaker(0.5, 30*time.Second)
+	b.MinRequests = 4
+	b.now = func() time.Time { return now }
+	b.OnStateChange = func(baseURL string, from, to CircuitState) {
+		assert.Equal(t, a.BaseURL, baseURL)
+		mu.Lock()
+		defer mu.Unlock()
+		*transitions = append(*transitions, transition{from, to})
+	}
+	a.CircuitBreaker = b
+	return a, b, transitions, &now
+}
+
+func call(a *API) error {
+	return DoNoContent(context.Background(), a, RequestConfig{Method: "GET", Path: "/test"})
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+	a, b, transitions, now := newBreakerAPI(t, &status)
+
+	// 2 of 4 requests failed
+	assert.NoError(t, call(a))
+	assert.NoError(t, call(a))
+	status.Store(http.StatusBadGateway)
+	assert.Error(t, call(a))
+	assert.Equal(t, CircuitClosed, b.State(a.BaseURL))
+	assert.Error(t, call(a))
+	assert.Equal(t, CircuitOpen, b.State(a.BaseURL))
+
+	// rejected without sending the request
+	status.Store(http.StatusOK)
+	err := call(a)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	var e *CircuitOpenError
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, a.BaseURL, e.BaseURL)
+	assert.Equal(t, now.Add(30*time.Second), e.Until)
+
+	// failed trial re-opens the circuit
+	*now = now.Add(30 * time.Second)
+	assert.Equal(t, CircuitHalfOpen, b.State(a.BaseURL))
+	status.Store(http.StatusServiceUnavailable)
+	assert.True(t, HasStatus(call(a), http.StatusServiceUnavailable))
+	assert.ErrorIs(t, call(a), ErrCircuitOpen)
+
+	// successful trial closes it
+	*now = now.Add(30 * time.Second)
+	status.Store(http.StatusOK)
+	assert.NoError(t, call(a))
+	assert.Equal(t, CircuitClosed, b.State(a.BaseURL))
+	assert.NoError(t, call(a))
+
+	assert.Equal(t, []transition{
+		{CircuitClosed, CircuitOpen},
+		{CircuitOpen, CircuitHalfOpen},
+		{CircuitHalfOpen, CircuitOpen},
+		{CircuitOpen, CircuitHalfOpen},
+		{CircuitHalfOpen, CircuitClosed},
+	}, *transitions)
+}
+
+func TestCircuitBreaker_ClientErrors(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusNotFound)
+	a, b, _, _ := newBreakerAPI(t, &status)
+
+	for i := 0; i < 10; i++ {
+		assert.True(t, IsNotFound(call(a)))
+	}
+	assert.Equal(t, CircuitClosed, b.State(a.BaseURL))
+}
+
+func TestCircuitBreaker_Window(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusInternalServerError)
+	a, b, _, now := newBreakerAPI(t, &status)
+
+	for i := 0; i < 3; i++ {
+		assert.Error(t, call(a))
+	}
+	// failures of the previous window are forgotten
+	*now = now.Add(time.Minute)
+	status.Store(http.StatusOK)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, call(a))
+	}
+	status.Store(http.StatusInternalServerError)
+	assert.Error(t, call(a))
+	assert.Equal(t, CircuitClosed, b.State(a.BaseURL))
+}
+
+func TestCircuitBreaker_PerBaseURL(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusInternalServerError)
+	a, b, _, _ := newBreakerAPI(t, &status)
+
+	for i := 0; i < 4; i++ {
+		assert.Error(t, call(a))
+	}
+	assert.Equal(t, CircuitOpen, b.State(a.BaseURL))
+
+	other, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {})
+	defer s.Close()
+	other.CircuitBreaker = b
+	assert.NoError(t, call(other))
+}
+
+func TestCircuitBreaker_NoRetryWhenOpen(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	a, _, _, _ := newBreakerAPI(t, &status)
+	a.RetryPolicy = &Backoff{MaxAttempts: 10, BaseBackoff: time.Millisecond}
+
+	// opens after 4th attempt, the 5th is rejected and not retried
+	err := call(a)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+}
+
+func TestCircuitBreaker_CancelledTrial(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusInternalServerError)
+	a, b, transitions, now := newBreakerAPI(t, &status)
+
+	for i := 0; i < 4; i++ {
+		assert.Error(t, call(a))
+	}
+	*now = now.Add(30 * time.Second)
+	status.Store(http.StatusOK)
+
+	// cancelled trial neither closes nor re-opens the circuit, the next trial is allowed
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := DoNoContent(ctx, a, RequestConfig{Method: "GET", Path: "/test"})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, CircuitHalfOpen, b.State(a.BaseURL))
+	assert.NoError(t, call(a))
+	assert.Equal(t, CircuitClosed, b.State(a.BaseURL))
+
+	assert.Equal(t, []transition{
+		{CircuitClosed, CircuitOpen},
+		{CircuitOpen, CircuitHalfOpen},
+		{CircuitHalfOpen, CircuitClosed},
+	}, *transitions)
+}
+
+func TestCircuitBreaker_SlowRequest(t *testing.T) {
+	now := time.Now()
+	b := NewCircuitBreaker(0.5, 30*time.Second)
+	b.MinRequests = 2
+	b.now = func() time.Time { return now }
+	const url = "https://api.test"
+	failure := ClientResponse{StatusCode: http.StatusServiceUnavailable, Error: errors.New("unavailable")}
+
+	// sent while closed, still in flight when the circuit opens
+	slow, err := b.allow(url)
+	assert.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		tk, err := b.allow(url)
+		assert.NoError(t, err)
+		b.done(url, tk, failure)
+	}
+	assert.Equal(t, CircuitOpen, b.State(url))
+
+	// finishing after the cool-down it's not a trial, so it neither closes the circuit nor frees the trial slot
+	now = now.Add(30 * time.Second)
+	trial, err := b.allow(url)
+	assert.NoError(t, err)
+	b.done(url, slow, ClientResponse{StatusCode: http.StatusOK})
+	assert.Equal(t, CircuitHalfOpen, b.State(url))
+	_, err = b.allow(url)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+
+	b.done(url, trial, failure)
+	assert.Equal(t, CircuitOpen, b.State(url))
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache caches successful GET responses of paths with configured TTL.
+//
+// Expired response with ETag is revalidated using If-None-Match, so unchanged response
+// isn't transferred again. Concurrent identical GETs are collapsed into a single call.
+// A mutating call that reached the server invalidates all cached responses, as it may change
+// other resources than its target, e.g. attaching disk to VM changes `/v1/storage/disks/<id>`.
+//
+//	a.Cache = api.NewCache(map[string]time.Duration{
+//		"/v1/config/locations": time.Hour,
+//		"/v1/storage/api/s3":   time.Hour,
+//		"/v1/storage/user":     10 * time.Minute,
+//	})
+type Cache struct {
+	ttls map[string]time.Duration
+	now  func() time.Time
+	// joined is called when a caller joins in-progress request, it lets tests order callers
+	joined func()
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+	flights map[string]*flight
+	gen     int
+}
+
+// cacheEntry is cached response
+type cacheEntry struct {
+	path       string
+	body       []byte
+	statusCode int
+	header     http.Header
+	etag       string
+	expires    time.Time
+}
+
+func (e *cacheEntry) response() ClientResponse {
+	return ClientResponse{Body: e.body, StatusCode: e.statusCode, Header: e.header}
+}
+
+// flight is an in-progress request other callers wait for
+type flight struct {
+	done chan struct{}
+	resp ClientResponse
+	// cancelled is set when the request failed because ctx of the caller sending it was done,
+	// waiters then send the request themselves as their ctx may still be live.
+	cancelled bool
+}
+
+// NewCache creates Cache with TTLs keyed by path prefix, the longest matching prefix wins.
+// Responses of paths without matching prefix are not cached.
+func NewCache(ttls map[string]time.Duration) *Cache {
+	return &Cache{
+		ttls:    ttls,
+		now:     time.Now,
+		entries: map[string]*cacheEntry{},
+		flights: map[string]*flight{},
+	}
+}
+
+// Invalidate removes cached responses of paths starting with prefix
+func (c *Cache) Invalidate(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	for k, e := range c.entries {
+		if strings.HasPrefix(e.path, prefix) {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// ttl returns TTL of path and whether it's cacheable at all
+func (c *Cache) ttl(path string) (time.Duration, bool) {
+	var ttl time.Duration
+	longest := -1
+	for prefix, d := range c.ttls {
+		if strings.HasPrefix(path, prefix) && len(prefix) > longest {
+			ttl, longest = d, len(prefix)
+		}
+	}
+	return ttl, longest >= 0 && ttl > 0
+}
+
+// get returns cached response when it's fresh, otherwise sends (or revalidates) the request.
+func (c *Cache) get(ctx context.Context, a *API, cfg RequestConfig, contentType string, read func(io.Reader) ([]byte, error)) ClientResponse {
+	path := "/" + strings.TrimLeft(cfg.Path, "/")
+	ttl, ok := c.ttl(path)
+	if !ok {
+		return a.exchange(ctx, cfg, contentType, nil, read)
+	}
+	// responses differ per API key
+	key := a.APIKey + " " + cfg.url("")
+
+	c.mu.Lock()
+	e := c.entries[key]
+	if e != nil && c.now().Before(e.expires) {
+		c.mu.Unlock()
+		return replay(e.response(), read)
+	}
+	if f, ok := c.flights[key]; ok {
+		c.mu.Unlock()
+		if c.joined != nil {
+			c.joined()
+		}
+		select {
+		case <-f.done:
+			if f.cancelled {
+				return c.get(ctx, a, cfg, contentType, read)
+			}
+			return replay(f.resp, read)
+		case <-ctx.Done():
+			return ClientResponse{Error: ctx.Err()}
+		}
+	}
+	f := &flight{done: make(chan struct{})}
+	c.flights[key] = f
+	gen := c.gen
+	c.mu.Unlock()
+
+	var header http.Header
+	if e != nil && e.etag != "" {
+		header = http.Header{"If-None-Match": []string{e.etag}}
+	}
+	resp := a.exchange(ctx, cfg, contentType, header, io.ReadAll)
+
+	c.mu.Lock()
+	switch {
+	case resp.Error != nil:
+	case resp.StatusCode == http.StatusNotModified && e != nil:
+		e.expires = c.now().Add(ttl)
+		resp = e.response()
+		if gen == c.gen {
+			c.entries[key] = e
+		}
+	case gen == c.gen:
+		c.entries[key] = &cacheEntry{
+			path:       path,
+			body:       resp.Body,
+			statusCode: resp.StatusCode,
+			header:     resp.Header,
+			etag:       resp.Header.Get("ETag"),
+			expires:    c.now().Add(ttl),
+		}
+	}
+	f.resp = resp
+	f.cancelled = resp.Error != nil && ctx.Err() != nil
+	delete(c.flights, key)
+	close(f.done)
+	c.mu.Unlock()
+
+	return replay(resp, read)
+}
+
+// replay passes body of the response through read
+func replay(resp ClientResponse, read func(io.Reader) ([]byte, error)) ClientResponse {
+	if resp.Error != nil {
+		return resp
+	}
+	b, err := read(bytes.NewReader(resp.Body))
+	return ClientResponse{Body: b, Error: err, StatusCode: resp.StatusCode, Header: resp.Header}
+}
@@ -0,0 +1,239 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type cacheClock struct {
+	t time.Time
+}
+
+func (c *cacheClock) now() time.Time { return c.t }
+
+func newCachedAPI(t *testing.T, handler http.HandlerFunc) (*API, *cacheClock) {
+	a, s := MockClientServer(handler)
+	t.Cleanup(s.Close)
+	clock := &cacheClock{t: time.Now()}
+	a.Cache = NewCache(map[string]time.Duration{
+		"/v1/storage":          time.Minute,
+		"/v1/storage/user":     10 * time.Second,
+		"/v1/storage/nocache/": 0,
+	})
+	a.Cache.now = clock.now
+	return a, clock
+}
+
+func get(a *API, path string) (map[string]any, error) {
+	return Do[map[string]any](context.Background(), a, RequestConfig{Method: "GET", Path: path})
+}
+
+func TestCache_TTL(t *testing.T) {
+	var calls atomic.Int32
+	a, clock := newCachedAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"path": "` + r.URL.Path + `"}`))
+	})
+
+	for i := 0; i < 3; i++ {
+		v, err := get(a, "/v1/storage/user")
+		assert.NoError(t, err)
+		assert.Equal(t, "/v1/storage/user", v["path"])
+	}
+	assert.Equal(t, int32(1), calls.Load())
+
+	// the longest prefix wins
+	clock.t = clock.t.Add(11 * time.Second)
+	_, _ = get(a, "/v1/storage/user")
+	assert.Equal(t, int32(2), calls.Load())
+	_, _ = get(a, "/v1/storage/api/s3")
+	_, _ = get(a, "/v1/storage/api/s3")
+	assert.Equal(t, int32(3), calls.Load())
+
+	// not cached paths
+	_, _ = get(a, "/v1/config/locations")
+	_, _ = get(a, "/v1/config/locations")
+	_, _ = get(a, "/v1/storage/nocache/x")
+	_, _ = get(a, "/v1/storage/nocache/x")
+	assert.Equal(t, int32(7), calls.Load())
+
+	// streaming decoder gets the cached body too
+	v, err := Do[map[string]any](context.Background(), a, RequestConfig{Method: "GET", Path: "/v1/storage/user", Stream: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "/v1/storage/user", v["path"])
+	assert.Equal(t, int32(7), calls.Load())
+}
+
+func TestCache_PerAPIKey(t *testing.T) {
+	a, _ := newCachedAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"key": "` + r.Header.Get("apikey") + `"}`))
+	})
+
+	v, _ := get(a, "/v1/storage/user")
+	assert.Equal(t, "secret", v["key"])
+	a.APIKey = "other"
+	v, _ = get(a, "/v1/storage/user")
+	assert.Equal(t, "other", v["key"])
+}
+
+func TestCache_ErrorNotCached(t *testing.T) {
+	var calls atomic.Int32
+	a, _ := newCachedAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	_, err := get(a, "/v1/storage/user")
+	assert.True(t, HasStatus(err, http.StatusServiceUnavailable))
+	_, err = get(a, "/v1/storage/user")
+	assert.True(t, HasStatus(err, http.StatusServiceUnavailable))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestCache_ETag(t *testing.T) {
+	var calls, notModified atomic.Int32
+	a, clock := newCachedAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"name": "test"}`))
+	})
+
+	_, _ = get(a, "/v1/storage/user")
+	clock.t = clock.t.Add(time.Minute)
+	v, err := get(a, "/v1/storage/user")
+	assert.NoError(t, err)
+	assert.Equal(t, "test", v["name"])
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, int32(1), notModified.Load())
+
+	// revalidated response is fresh again
+	_, _ = get(a, "/v1/storage/user")
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestCache_Invalidation(t *testing.T) {
+	var calls atomic.Int32
+	a, _ := newCachedAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{}`))
+	})
+
+	_, _ = get(a, "/v1/storage/user")
+	_, _ = get(a, "/v1/storage/user/keys")
+	_, _ = get(a, "/v1/storage/api/s3")
+	assert.Equal(t, int32(3), calls.Load())
+
+	// generating key invalidates everything, including responses of other resources
+	assert.NoError(t, DoNoContent(context.Background(), a, RequestConfig{Method: "POST", Path: "/v1/storage/user/keys"}))
+	assert.Equal(t, int32(4), calls.Load())
+	_, _ = get(a, "/v1/storage/user")
+	_, _ = get(a, "/v1/storage/user/keys")
+	_, _ = get(a, "/v1/storage/api/s3")
+	assert.Equal(t, int32(7), calls.Load())
+
+	// dry run doesn't invalidate
+	a.DryRun = &Plan{}
+	_ = DoNoContent(context.Background(), a, RequestConfig{Method: "DELETE", Path: "/v1/storage/user/keys"})
+	_, _ = get(a, "/v1/storage/user")
+	assert.Equal(t, int32(7), calls.Load())
+
+	a.Cache.Invalidate("/v1")
+	_, _ = get(a, "/v1/storage/api/s3")
+	assert.Equal(t, int32(8), calls.Load())
+}
+
+func TestCache_SingleFlight(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	a, _ := newCachedAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		w.Write([]byte(`{"name": "test"}`))
+	})
+	joined := make(chan struct{}, 10)
+	a.Cache.joined = func() { joined <- struct{}{} }
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := get(a, "/v1/storage/user")
+			assert.NoError(t, err)
+			assert.Equal(t, "test", v["name"])
+		}()
+	}
+	// release the first request once all the others wait for it
+	for i := 0; i < 9; i++ {
+		<-joined
+	}
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestCache_SingleFlight_LeaderCancelled(t *testing.T) {
+	var calls atomic.Int32
+	sent := make(chan struct{})
+	a, _ := newCachedAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			close(sent)
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte(`{"name": "test"}`))
+	})
+	joined := make(chan struct{}, 1)
+	a.Cache.joined = func() { joined <- struct{}{} }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leader := make(chan error)
+	go func() {
+		_, err := Do[map[string]any](ctx, a, RequestConfig{Method: "GET", Path: "/v1/storage/user"})
+		leader <- err
+	}()
+	<-sent
+
+	waiter := make(chan error)
+	go func() {
+		v, err := get(a, "/v1/storage/user")
+		assert.Equal(t, "test", v["name"])
+		waiter <- err
+	}()
+	// give up on the leader once the waiter joined it
+	<-joined
+	cancel()
+
+	assert.ErrorIs(t, <-leader, context.Canceled)
+	assert.NoError(t, <-waiter)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestCache_CrossResourceInvalidation(t *testing.T) {
+	var status atomic.Value
+	status.Store("Detached")
+	a, _ := newCachedAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			status.Store("Attached")
+			return
+		}
+		w.Write([]byte(`{"status": "` + status.Load().(string) + `"}`))
+	})
+
+	v, _ := get(a, "/v1/storage/disks/4e5eadd3")
+	assert.Equal(t, "Detached", v["status"])
+	assert.NoError(t, DoNoContent(context.Background(), a, RequestConfig{Method: "POST", Path: "/v1/user-resource/vm/storage/attach"}))
+	v, _ = get(a, "/v1/storage/disks/4e5eadd3")
+	assert.Equal(t, "Attached", v["status"])
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// Do sends the request and decodes json response body into T.
+// Payload is form-encoded when cfg.Data is set otherwise json-encoded.
+// Empty response body (e.g. 204 No Content) results in zero value of T.
+func Do[T any](ctx context.Context, a *API, cfg RequestConfig) (T, error) {
+	var v T
+	if err := DoInto(ctx, a, cfg, &v); err != nil {
+		var zero T
+		return zero, err
+	}
+	return v, nil
+}
+
+// DoInto is like Do but decodes the response body into v, which left untouched when the body is empty.
+func DoInto(ctx context.Context, a *API, cfg RequestConfig, v any) error {
+	if !cfg.Stream {
+		resp := a.send(ctx, cfg, cfg.contentType(), io.ReadAll)
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if err := a.decode(bytes.NewReader(resp.Body), v); err != nil {
+			return &responseError{err}
+		}
+		return nil
+	}
+	resp := a.send(ctx, cfg, cfg.contentType(), func(r io.Reader) ([]byte, error) {
+		return nil, a.decode(r, v)
+	})
+	return resp.Error
+}
+
+// DoNoContent sends the request and discards the response body.
+func DoNoContent(ctx context.Context, a *API, cfg RequestConfig) error {
+	resp := a.send(ctx, cfg, cfg.contentType(), func(r io.Reader) ([]byte, error) {
+		_, err := io.Copy(io.Discard, r)
+		return nil, err
+	})
+	return resp.Error
+}
+
+// decode decodes json from r into v, empty input is not an error.
+func (a *API) decode(r io.Reader, v any) error {
+	dec := json.NewDecoder(r)
+	if a.StrictDecoding {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testItem struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func TestDo(t *testing.T) {
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		w.Write([]byte(`[{"name": "a", "age": 1}, {"name": "b", "age": 2}]`))
+	})
+	defer s.Close()
+
+	items, err := Do[[]testItem](context.Background(), c, RequestConfig{Method: "GET", Path: "/test"})
+	assert.NoError(t, err)
+	assert.Equal(t, []testItem{{"a", 1}, {"b", 2}}, items)
+}
+
+func TestDo_Form(t *testing.T) {
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+		_ = r.ParseForm()
+		w.Write([]byte(`{"name": "` + r.Form.Get("name") + `"}`))
+	})
+	defer s.Close()
+
+	item, err := Do[testItem](context.Background(), c, RequestConfig{
+		Method: "POST",
+		Path:   "/test",
+		Data:   url.Values{"name": []string{"a"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "a", item.Name)
+}
+
+func TestDo_Error(t *testing.T) {
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"name": "a"}`))
+	})
+	defer s.Close()
+
+	item, err := Do[testItem](context.Background(), c, RequestConfig{Method: "GET", Path: "/test"})
+	assert.True(t, IsNotFound(err))
+	assert.Equal(t, testItem{}, item)
+}
+
+func TestDo_NoContent(t *testing.T) {
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	defer s.Close()
+
+	item, err := Do[*testItem](context.Background(), c, RequestConfig{Method: "GET", Path: "/test"})
+	assert.NoError(t, err)
+	assert.Nil(t, item)
+
+	assert.NoError(t, DoNoContent(context.Background(), c, RequestConfig{Method: "DELETE", Path: "/test"}))
+}
+
+func TestDo_InvalidJSON(t *testing.T) {
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name": `))
+	})
+	defer s.Close()
+
+	_, err := Do[testItem](context.Background(), c, RequestConfig{Method: "GET", Path: "/test"})
+	assert.Error(t, err)
+}
+
+func TestDo_Strict(t *testing.T) {
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name": "a", "unknown": true}`))
+	})
+	defer s.Close()
+
+	_, err := Do[testItem](context.Background(), c, RequestConfig{Method: "GET", Path: "/test"})
+	assert.NoError(t, err)
+
+	c.StrictDecoding = true
+	_, err = Do[testItem](context.Background(), c, RequestConfig{Method: "GET", Path: "/test"})
+	assert.ErrorContains(t, err, "unknown")
+}
+
+func TestDo_Stream(t *testing.T) {
+	body := "[" + strings.Repeat(`{"name": "a", "age": 1},`, 999) + `{"name": "a", "age": 1}]`
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	})
+	defer s.Close()
+
+	items, err := Do[[]testItem](context.Background(), c, RequestConfig{Method: "GET", Path: "/test", Stream: true})
+	assert.NoError(t, err)
+	assert.Len(t, items, 1000)
+}
+
+func TestDoInto(t *testing.T) {
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"age": 2}`))
+	})
+	defer s.Close()
+
+	// existing fields are kept
+	item := testItem{Name: "a"}
+	assert.NoError(t, DoInto(context.Background(), c, RequestConfig{Method: "GET", Path: "/test"}, &item))
+	assert.Equal(t, testItem{"a", 2}, item)
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrDryRun is returned by mutating calls in dry-run mode, the call is added to the plan instead of being sent.
+var ErrDryRun = errors.New("dry run: request not sent")
+
+// PlannedCall is a mutating call recorded in dry-run mode
+type PlannedCall struct {
+	Operation string
+	Method    string
+	Path      string
+	Query     string
+	Payload   string
+}
+
+// String returns the call as `METHOD /path?query payload`
+func (c PlannedCall) String() string {
+	s := c.Method + " " + c.Path
+	if c.Query != "" {
+		s += "?" + c.Query
+	}
+	if c.Payload != "" {
+		s += " " + c.Payload
+	}
+	return s
+}
+
+// Plan collects mutating calls made in dry-run mode, it's safe for concurrent use.
+//
+//	plan := &api.Plan{}
+//	a.DryRun = plan
+//	err := w.VPC.DeleteNetwork(ctx, id) // errors.Is(err, api.ErrDryRun)
+//	fmt.Print(plan)
+type Plan struct {
+	mu    sync.Mutex
+	calls []PlannedCall
+}
+
+// add records the call, secrets in the payload (e.g. VM password) are redacted like in debug logs.
+func (p *Plan) add(cfg RequestConfig, body []byte) {
+	c := PlannedCall{
+		Operation: cfg.Operation,
+		Method:    strings.ToUpper(cfg.Method),
+		Path:      "/" + strings.TrimLeft(cfg.Path, "/"),
+		Payload:   string(redactSecrets(body)),
+	}
+	if cfg.Query != nil {
+		c.Query = cfg.Query.Encode()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, c)
+}
+
+// Calls returns recorded calls in order
+func (p *Plan) Calls() []PlannedCall {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]PlannedCall{}, p.calls...)
+}
+
+// Reset forgets all recorded calls
+func (p *Plan) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = nil
+}
+
+// String returns recorded calls one per line
+func (p *Plan) String() string {
+	var b strings.Builder
+	for _, c := range p.Calls() {
+		b.WriteString(c.String())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// dryRun records the call and returns ErrDryRun when the request must not be sent
+func (a *API) dryRun(cfg RequestConfig, body []byte) error {
+	if a.DryRun == nil || !isMutating(cfg.Method) {
+		return nil
+	}
+	a.DryRun.add(cfg, body)
+	return fmt.Errorf("%w: %s %s", ErrDryRun, strings.ToUpper(cfg.Method), cfg.Path)
+}
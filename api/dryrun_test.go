@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDryRun(t *testing.T) {
+	var sent atomic.Int32
+	a, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		sent.Add(1)
+		w.Write([]byte(`{"name": "test"}`))
+	})
+	defer s.Close()
+	plan := &Plan{}
+	a.DryRun = plan
+
+	// reads still go through
+	v, err := Do[map[string]string](context.Background(), a, RequestConfig{Method: "GET", Path: "/test"})
+	assert.NoError(t, err)
+	assert.Equal(t, "test", v["name"])
+
+	err = DoNoContent(context.Background(), a, RequestConfig{Method: "DELETE", Path: "/test/1", Operation: "test.Delete"})
+	assert.ErrorIs(t, err, ErrDryRun)
+	resp := a.FormRequest(context.Background(), RequestConfig{
+		Method: "post",
+		Path:   "test",
+		Query:  url.Values{"a": {"b"}},
+		Data:   url.Values{"name": {"test"}},
+	})
+	assert.ErrorIs(t, resp.Error, ErrDryRun)
+	_, err = Do[map[string]string](context.Background(), a, RequestConfig{Method: "PATCH", Path: "/test/1", JSON: map[string]any{"name": "new"}})
+	assert.ErrorIs(t, err, ErrDryRun)
+
+	assert.Equal(t, int32(1), sent.Load())
+	assert.Equal(t, []PlannedCall{
+		{Operation: "test.Delete", Method: "DELETE", Path: "/test/1"},
+		{Method: "POST", Path: "/test", Query: "a=b", Payload: "name=test"},
+		{Method: "PATCH", Path: "/test/1", Payload: `{"name":"new"}`},
+	}, plan.Calls())
+	assert.Equal(t, "DELETE /test/1\nPOST /test?a=b name=test\nPATCH /test/1 {\"name\":\"new\"}\n", plan.String())
+
+	plan.Reset()
+	assert.Empty(t, plan.Calls())
+}
+
+func TestDryRun_RedactsSecrets(t *testing.T) {
+	a, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {})
+	defer s.Close()
+	plan := &Plan{}
+	a.DryRun = plan
+
+	resp := a.FormRequest(context.Background(), RequestConfig{
+		Method: "POST",
+		Path:   "/v1/jkt01/user-resource/vm",
+		Data:   url.Values{"name": {"web"}, "password": {"s3cret!"}, "cloud_init": {"#cloud-config"}},
+	})
+	assert.ErrorIs(t, resp.Error, ErrDryRun)
+	_, err := Do[map[string]string](context.Background(), a, RequestConfig{Method: "POST", Path: "/test", JSON: map[string]any{"password": "s3cret!"}})
+	assert.ErrorIs(t, err, ErrDryRun)
+
+	calls := plan.Calls()
+	assert.Equal(t, "cloud_init=[REDACTED]&name=web&password=[REDACTED]", calls[0].Payload)
+	assert.Equal(t, `{"password":"[REDACTED]"}`, calls[1].Payload)
+	assert.NotContains(t, plan.String(), "s3cret")
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ErrMissingLocation is returned by location-scoped clients (e.g. vpc, ip) when the location is not set.
+var ErrMissingLocation = errors.New("data center location is required")
+
+// ErrUnsupportedOption is returned when a call option overrides scope the called method doesn't use,
+// e.g. api.InLocation passed to an object storage call. See CheckOptions.
+var ErrUnsupportedOption = errors.New("call option is not supported")
+
+// Error is returned by every API call that ends up with a non-success (>= 400) status code.
+// Use `errors.As` to inspect it or one of the `Is*` helpers to branch on common failures.
+type Error struct {
+	StatusCode int
+	Method     string
+	Path       string
+	Body       []byte
+	Header     http.Header
+
+	// Message and Code are parsed from the Warren error payload when available.
+	Message string
+	Code    string
+}
+
+// Error implements error interface
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = string(e.Body)
+	}
+	if msg == "" {
+		return fmt.Sprintf("%s %s: api call failed with status code=%d", e.Method, e.Path, e.StatusCode)
+	}
+	return fmt.Sprintf("%s %s: api call failed with status code=%d: %s", e.Method, e.Path, e.StatusCode, msg)
+}
+
+// newError builds Error from response status, headers and body.
+func newError(req *http.Request, res *http.Response, body []byte) *Error {
+	e := &Error{
+		StatusCode: res.StatusCode,
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		Body:       body,
+		Header:     res.Header,
+	}
+	e.Message, e.Code = parseErrorBody(body)
+	return e
+}
+
+// parseErrorBody tries to extract message and code from Warren error payload, e.g.
+// `{"message": "Network not found", "code": "not_found"}` or `{"error": "..."}`.
+func parseErrorBody(body []byte) (string, string) {
+	var payload struct {
+		Message string          `json:"message"`
+		Error   string          `json:"error"`
+		Code    json.RawMessage `json:"code"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", ""
+	}
+	msg := payload.Message
+	if msg == "" {
+		msg = payload.Error
+	}
+
+	// code could be either string or number
+	var code string
+	if err := json.Unmarshal(payload.Code, &code); err != nil {
+		var n json.Number
+		if err := json.Unmarshal(payload.Code, &n); err == nil {
+			code = n.String()
+		}
+	}
+	return msg, code
+}
+
+// HasStatus reports whether err is an API Error with given status code.
+func HasStatus(err error, status int) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.StatusCode == status
+	}
+	return false
+}
+
+// IsNotFound reports whether err is caused by 404 response.
+func IsNotFound(err error) bool {
+	return HasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether err is caused by 409 response.
+func IsConflict(err error) bool {
+	return HasStatus(err, http.StatusConflict)
+}
+
+// IsUnauthorized reports whether err is caused by 401 or 403 response.
+func IsUnauthorized(err error) bool {
+	return HasStatus(err, http.StatusUnauthorized) || HasStatus(err, http.StatusForbidden)
+}
+
+// IsRateLimited reports whether err is caused by 429 response.
+func IsRateLimited(err error) bool {
+	return HasStatus(err, http.StatusTooManyRequests)
+}
+
+// MayHaveSucceeded reports whether the request failed with err may still have been applied by the server,
+// i.e. it was sent but failed in transport (e.g. timeout, dropped connection), got 5xx response or its
+// response couldn't be read. It's false for errors returned before sending, such as validation errors.
+func MayHaveSucceeded(err error) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	var ue *url.Error
+	var re *responseError
+	return errors.As(err, &ue) || errors.As(err, &re)
+}
+
+// responseError is failure to read or decode response of a sent request
+type responseError struct {
+	err error
+}
+
+func (e *responseError) Error() string {
+	return e.err.Error()
+}
+
+func (e *responseError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError(t *testing.T) {
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "abc")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "Network not found", "code": 404}`))
+	})
+	defer s.Close()
+
+	resp := c.JSONRequest(context.Background(), RequestConfig{Method: "GET", Path: "/test"})
+
+	var e *Error
+	assert.True(t, errors.As(resp.Error, &e))
+	assert.Equal(t, http.StatusNotFound, e.StatusCode)
+	assert.Equal(t, "GET", e.Method)
+	assert.Equal(t, "/test", e.Path)
+	assert.Equal(t, "Network not found", e.Message)
+	assert.Equal(t, "404", e.Code)
+	assert.Equal(t, "abc", e.Header.Get("X-Request-Id"))
+	assert.Equal(t, "GET /test: api call failed with status code=404: Network not found", e.Error())
+	assert.True(t, IsNotFound(resp.Error))
+	assert.False(t, IsConflict(resp.Error))
+}
+
+func TestError_NonJSONBody(t *testing.T) {
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("bad gateway"))
+	})
+	defer s.Close()
+
+	resp := c.FormRequest(context.Background(), RequestConfig{Method: "DELETE", Path: "/test"})
+
+	var e *Error
+	assert.True(t, errors.As(resp.Error, &e))
+	assert.Equal(t, "", e.Message)
+	assert.Equal(t, []byte("bad gateway"), resp.Body)
+	assert.Equal(t, "DELETE /test: api call failed with status code=502: bad gateway", e.Error())
+}
+
+func TestErrorHelpers(t *testing.T) {
+	wrap := func(code int) error {
+		return fmt.Errorf("wrapped: %w", &Error{StatusCode: code})
+	}
+	assert.True(t, IsNotFound(wrap(http.StatusNotFound)))
+	assert.True(t, IsConflict(wrap(http.StatusConflict)))
+	assert.True(t, IsUnauthorized(wrap(http.StatusUnauthorized)))
+	assert.True(t, IsUnauthorized(wrap(http.StatusForbidden)))
+	assert.True(t, IsRateLimited(wrap(http.StatusTooManyRequests)))
+	assert.False(t, IsNotFound(errors.New("not found")))
+	assert.False(t, IsNotFound(nil))
+}
+
+func TestMayHaveSucceeded(t *testing.T) {
+	assert.True(t, MayHaveSucceeded(&Error{StatusCode: http.StatusBadGateway}))
+	assert.True(t, MayHaveSucceeded(&url.Error{Op: "Post", URL: "/test", Err: context.DeadlineExceeded}))
+	assert.True(t, MayHaveSucceeded(&responseError{io.ErrUnexpectedEOF}))
+	assert.False(t, MayHaveSucceeded(&Error{StatusCode: http.StatusConflict}))
+	assert.False(t, MayHaveSucceeded(fmt.Errorf("%w: DELETE /test", ErrDryRun)))
+	assert.False(t, MayHaveSucceeded(&CircuitOpenError{}))
+	assert.False(t, MayHaveSucceeded(ErrMissingLocation))
+	assert.False(t, MayHaveSucceeded(fmt.Errorf("%w: location", ErrUnsupportedOption)))
+	assert.False(t, MayHaveSucceeded(nil))
+	// validation failure of a client or caller, the request wasn't sent
+	assert.False(t, MayHaveSucceeded(fmt.Errorf("BillingAccountID with value of %v is invalid", 0)))
+	assert.False(t, MayHaveSucceeded(context.DeadlineExceeded))
+}
+
+func TestMayHaveSucceeded_Sent(t *testing.T) {
+	a, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/drop":
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+		case "/invalid":
+			w.Write([]byte(`{"name": `))
+		}
+	})
+	defer s.Close()
+
+	var v map[string]any
+	for _, path := range []string{"/drop", "/invalid"} {
+		err := DoInto(context.Background(), a, RequestConfig{Method: "POST", Path: path}, &v)
+		assert.Error(t, err)
+		assert.True(t, MayHaveSucceeded(err), path)
+	}
+}
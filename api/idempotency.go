@@ -0,0 +1,18 @@
+package api
+
+import "github.com/google/uuid"
+
+// IdempotencyKeyHeader is the header name commonly used by providers that support idempotent requests,
+// set it as API.IdempotencyHeader when the provider supports it.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// NewIdempotencyKey returns a new random idempotency key.
+//
+// The Ensure methods of the service clients (e.g. objectstorage.Client.EnsureBucket) send the create
+// request with a new key, so a retried create is applied once when the provider supports idempotent
+// requests. Whether it does or not, the resource is looked up again when the create failed in a way it
+// may have been applied (see MayHaveSucceeded). A conflict means a resource with the same name was
+// created by someone else in the meantime, it's returned with created false.
+func NewIdempotencyKey() string {
+	return uuid.NewString()
+}
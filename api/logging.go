@@ -0,0 +1,120 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// maxLoggedBody is the maximum number of body bytes included in log records.
+const maxLoggedBody = 1024
+
+var (
+	redacted = "[REDACTED]"
+
+	// secretPatterns matches secrets in json and form-encoded payloads, e.g. `"secretKey": "..."` of S3Credential
+	// or `password` and `cloud_init` user data of a new VM. Escaped quotes are part of the json value, and a value
+	// cut off by truncated body is matched up to the end.
+	secretPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`("(?:secretKey|secret_key|apikey|password|cloud_init)"\s*:\s*")(?:[^"\\]|\\.)*\\?("|$)`),
+		regexp.MustCompile(`((?:^|&)(?:secretKey|secret_key|apikey|password|cloud_init)=)[^&]*()`),
+	}
+)
+
+// Logging is middleware that logs every request and its outcome at debug level.
+// The `apikey` header and secrets in body (e.g. S3 secretKey) are always redacted.
+// It's added automatically (after user middlewares, before built-ins) when API.Logger is set.
+func Logging(logger *slog.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request) (*http.Response, error) {
+			ctx := req.HTTP.Context()
+			if !logger.Enabled(ctx, slog.LevelDebug) {
+				return next(req)
+			}
+
+			start := time.Now()
+			res, err := next(req)
+
+			attrs := []slog.Attr{
+				slog.String("method", req.HTTP.Method),
+				slog.String("path", req.HTTP.URL.Path),
+				slog.String("query", req.HTTP.URL.RawQuery),
+				slog.Any("header", redactHeader(req.HTTP.Header)),
+				slog.Duration("latency", time.Since(start)),
+			}
+			if b, _ := req.Config.body(); len(b) > 0 {
+				attrs = append(attrs, slog.String("request_body", redactBody(b)))
+			}
+			if res != nil {
+				attrs = append(attrs, slog.Int("status", res.StatusCode))
+				if b := peekBody(res, maxLoggedBody+1); len(b) > 0 {
+					attrs = append(attrs, slog.String("response_body", redactBody(b)))
+				}
+			}
+			if err != nil {
+				attrs = append(attrs, slog.String("error", err.Error()))
+			}
+			logger.LogAttrs(ctx, slog.LevelDebug, "warren api request", attrs...)
+			return res, err
+		}
+	}
+}
+
+// peekBody reads up to n bytes of the response body and puts them back, so the next reader still gets
+// the full body without the rest of it being buffered (e.g. streamed list).
+func peekBody(res *http.Response, n int64) []byte {
+	if res.Body == nil {
+		return nil
+	}
+	b, err := io.ReadAll(io.LimitReader(res.Body, n))
+	res.Body = readCloser{io.MultiReader(bytes.NewReader(b), res.Body), res.Body}
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+// readCloser reads from Reader and closes Closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// redactHeader returns copy of h with `apikey` value replaced.
+func redactHeader(h http.Header) http.Header {
+	c := h.Clone()
+	if c.Get("apikey") != "" {
+		c.Set("apikey", redacted)
+	}
+	return c
+}
+
+// redactBody replaces known secret values in b and truncates it to maxLoggedBody.
+func redactBody(b []byte) string {
+	b = redactSecrets(b)
+	if len(b) > maxLoggedBody {
+		return string(b[:maxLoggedBody]) + "...(truncated)"
+	}
+	return string(b)
+}
+
+// redactSecrets replaces known secret values in b
+func redactSecrets(b []byte) []byte {
+	for _, p := range secretPatterns {
+		b = p.ReplaceAll(b, []byte("${1}"+redacted+"${2}"))
+	}
+	return b
+}
+
+// debugLogger returns logger writing to stderr when WARREN_DEBUG is set to true value, otherwise nil.
+func debugLogger() *slog.Logger {
+	if on, _ := strconv.ParseBool(os.Getenv(DebugEnvKey)); !on {
+		return nil
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
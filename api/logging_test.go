@@ -0,0 +1,111 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogging(t *testing.T) {
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"accessKey": "AK", "secretKey": "SK123", "userId": "u"}]`))
+	})
+	defer s.Close()
+
+	var buf bytes.Buffer
+	c.Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	resp := c.FormRequest(context.Background(), RequestConfig{
+		Method: "POST",
+		Path:   "/v1/storage/user/keys",
+		Query:  url.Values{"a": []string{"b"}},
+		Data:   url.Values{"name": []string{"test"}},
+	})
+
+	// body is still readable after being logged
+	assert.Contains(t, string(resp.Body), "SK123")
+
+	out := buf.String()
+	assert.Contains(t, out, "method=POST")
+	assert.Contains(t, out, "path=/v1/storage/user/keys")
+	assert.Contains(t, out, `query="a=b"`)
+	assert.Contains(t, out, "status=200")
+	assert.Contains(t, out, "latency=")
+	assert.Contains(t, out, `request_body="name=test"`)
+	assert.Contains(t, out, "AK")
+	assert.NotContains(t, out, "SK123")
+	assert.NotContains(t, out, "Apikey:[secret]")
+}
+
+func TestLogging_LargeBody(t *testing.T) {
+	body := `[` + strings.Repeat(`{"name": "bucket"},`, 10000) + `{"name": "last"}]`
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	})
+	defer s.Close()
+
+	var buf bytes.Buffer
+	c.Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	var buckets []map[string]string
+	err := DoInto(context.Background(), c, RequestConfig{Method: "GET", Path: "/test", Stream: true}, &buckets)
+	assert.NoError(t, err)
+	assert.Len(t, buckets, 10001)
+	assert.Contains(t, buf.String(), "...(truncated)")
+	assert.Less(t, buf.Len(), 2*maxLoggedBody)
+}
+
+func TestPeekBody(t *testing.T) {
+	body := strings.NewReader(strings.Repeat("a", 10*maxLoggedBody))
+	res := &http.Response{Body: io.NopCloser(body)}
+
+	// only the peeked bytes are read from the body
+	assert.Equal(t, strings.Repeat("a", 10), string(peekBody(res, 10)))
+	assert.Equal(t, 10*maxLoggedBody-10, body.Len())
+
+	b, err := io.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.Len(t, b, 10*maxLoggedBody)
+	assert.NoError(t, res.Body.Close())
+}
+
+func TestLogging_Disabled(t *testing.T) {
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})
+	defer s.Close()
+
+	var buf bytes.Buffer
+	c.Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	resp := c.JSONRequest(context.Background(), RequestConfig{Method: "GET", Path: "/test"})
+	assert.Equal(t, []byte("OK"), resp.Body)
+	assert.Empty(t, buf.String())
+}
+
+func TestRedactBody(t *testing.T) {
+	assert.Equal(t, `{"secretKey": "[REDACTED]", "a": "b"}`, redactBody([]byte(`{"secretKey": "x", "a": "b"}`)))
+	assert.Equal(t, `a=b&password=[REDACTED]&c=d`, redactBody([]byte(`a=b&password=x&c=d`)))
+
+	assert.Equal(t, `{"password": "[REDACTED]", "a": "b"}`, redactBody([]byte(`{"password": "x\"y\\", "a": "b"}`)))
+	// secret cut off by truncation
+	assert.Equal(t, `{"a": "b", "secretKey": "[REDACTED]`, redactBody([]byte(`{"a": "b", "secretKey": "xy`)))
+
+	long := strings.Repeat("a", maxLoggedBody+10)
+	assert.Equal(t, strings.Repeat("a", maxLoggedBody)+"...(truncated)", redactBody([]byte(long)))
+}
+
+func TestDebugLogger(t *testing.T) {
+	t.Setenv(DebugEnvKey, "")
+	assert.Nil(t, debugLogger())
+
+	t.Setenv(DebugEnvKey, "true")
+	assert.NotNil(t, debugLogger())
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// Request is passed along the middleware chain.
+type Request struct {
+	Config RequestConfig
+	HTTP   *http.Request
+}
+
+// Handler sends the request and returns the response.
+// Response may be non-nil even when error is returned (e.g. *Error from CheckStatus).
+type Handler func(req *Request) (*http.Response, error)
+
+// Middleware wraps a Handler to run code before and/or after the next one in chain.
+type Middleware func(next Handler) Handler
+
+// Use appends middlewares to the chain, the first registered is the outermost.
+// It should be called before the API is used to make requests as it's not safe for concurrent use.
+func (a *API) Use(mw ...Middleware) {
+	a.Middlewares = append(a.Middlewares, mw...)
+}
+
+// DefaultBuiltins returns built-in middlewares used when API.Builtins is nil.
+func (a *API) DefaultBuiltins() []Middleware {
+	return []Middleware{APIKeyAuth(a.APIKey), CheckStatus}
+}
+
+// handler composes user middlewares, built-in middlewares and the HTTP client into a single Handler.
+func (a *API) handler() Handler {
+	var h Handler = func(req *Request) (*http.Response, error) {
+		return a.HTTPClient.Do(req.HTTP)
+	}
+
+	builtins := a.Builtins
+	if builtins == nil {
+		builtins = a.DefaultBuiltins()
+	}
+	mws := append([]Middleware{}, a.Middlewares...)
+	if a.Logger != nil {
+		mws = append(mws, Logging(a.Logger))
+	}
+	mws = append(mws, builtins...)
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// APIKeyAuth is built-in middleware that set the `apikey` header used for authentication.
+func APIKeyAuth(key string) Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request) (*http.Response, error) {
+			req.HTTP.Header.Set("apikey", key)
+			return next(req)
+		}
+	}
+}
+
+// CheckStatus is built-in middleware that turns response with status code >= 400 into *Error.
+// The response is still returned with its body buffered so it can be read again.
+func CheckStatus(next Handler) Handler {
+	return func(req *Request) (*http.Response, error) {
+		res, err := next(req)
+		if err != nil || res.StatusCode < 400 {
+			return res, err
+		}
+		// body is best-effort here, the status code is what matters
+		b, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		res.Body = io.NopCloser(bytes.NewReader(b))
+		return res, newError(req.HTTP, res, b)
+	}
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUse(t *testing.T) {
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("apikey"))
+		assert.Equal(t, "custom", r.Header.Get("X-Custom"))
+		w.WriteHeader(http.StatusConflict)
+	})
+	defer s.Close()
+
+	var order []string
+	var status int
+	var seenErr error
+	c.Use(func(next Handler) Handler {
+		return func(req *Request) (*http.Response, error) {
+			order = append(order, "first")
+			assert.Equal(t, "/test", req.Config.Path)
+			res, err := next(req)
+			status, seenErr = res.StatusCode, err
+			return res, err
+		}
+	}, func(next Handler) Handler {
+		return func(req *Request) (*http.Response, error) {
+			order = append(order, "second")
+			req.HTTP.Header.Set("X-Custom", "custom")
+			return next(req)
+		}
+	})
+
+	resp := c.JSONRequest(context.Background(), RequestConfig{Method: "POST", Path: "/test"})
+	assert.Equal(t, []string{"first", "second"}, order)
+	assert.Equal(t, http.StatusConflict, status)
+	assert.True(t, IsConflict(seenErr))
+	assert.True(t, IsConflict(resp.Error))
+}
+
+func TestBuiltins_Replace(t *testing.T) {
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "other", r.Header.Get("apikey"))
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("not found"))
+	})
+	defer s.Close()
+
+	// without CheckStatus, 404 is not treated as an error
+	c.Builtins = []Middleware{APIKeyAuth("other")}
+	resp := c.JSONRequest(context.Background(), RequestConfig{Method: "GET", Path: "/test"})
+	assert.NoError(t, resp.Error)
+	assert.Equal(t, []byte("not found"), resp.Body)
+}
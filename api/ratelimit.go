@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit is a token bucket setting, zero RPS means unlimited.
+type RateLimit struct {
+	// RPS is number of requests per second allowed on average.
+	RPS float64
+	// Burst is the maximum number of requests that can be sent at once, minimum 1.
+	Burst int
+}
+
+// RateLimiter is client-side token bucket limiter with separate bucket per API key.
+// It's safe for concurrent use, so a single limiter could be shared by multiple API (and Warren) instances.
+type RateLimiter struct {
+	limit    RateLimit
+	mutating *RateLimit
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// NewRateLimiter creates RateLimiter with given limit.
+// When mutating is not nil, POST, PUT, PATCH and DELETE requests use their own bucket with that limit
+// instead of sharing the budget with read requests.
+func NewRateLimiter(limit RateLimit, mutating *RateLimit) *RateLimiter {
+	return &RateLimiter{
+		limit:    limit,
+		mutating: mutating,
+		buckets:  map[string]*bucket{},
+	}
+}
+
+// Wait blocks until a request with given method is allowed to be sent for apiKey, or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context, apiKey, method string) error {
+	limit, key := l.limit, "read:"+apiKey
+	if l.mutating != nil && isMutating(method) {
+		limit, key = *l.mutating, "mutating:"+apiKey
+	}
+	if limit.RPS <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = newBucket(limit)
+		l.buckets[key] = b
+	}
+	l.mu.Unlock()
+
+	return b.wait(ctx)
+}
+
+// bucket is a token bucket, tokens may go negative to represent reservations of waiting callers.
+type bucket struct {
+	mu     sync.Mutex
+	rps    float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(limit RateLimit) *bucket {
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &bucket{
+		rps:    limit.RPS,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// wait reserves a token and sleeps until it's available, the reservation is returned when ctx is done first.
+func (b *bucket) wait(ctx context.Context) error {
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rps
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	b.tokens--
+	tokens := b.tokens
+	b.mu.Unlock()
+
+	if tokens >= 0 {
+		return nil
+	}
+	d := time.Duration(-tokens / b.rps * float64(time.Second))
+	if err := sleep(ctx, d); err != nil {
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// isMutating reports whether method changes resource state
+func isMutating(method string) bool {
+	switch strings.ToUpper(method) {
+	case "POST", "PUT", "PATCH", "DELETE":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter_Burst(t *testing.T) {
+	l := NewRateLimiter(RateLimit{RPS: 10, Burst: 3}, nil)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, l.Wait(context.Background(), "key", "GET"))
+	}
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+
+	// 4th request has to wait for a token
+	assert.NoError(t, l.Wait(context.Background(), "key", "GET"))
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
+
+func TestRateLimiter_PerAPIKey(t *testing.T) {
+	l := NewRateLimiter(RateLimit{RPS: 1, Burst: 1}, nil)
+
+	start := time.Now()
+	assert.NoError(t, l.Wait(context.Background(), "keyA", "GET"))
+	assert.NoError(t, l.Wait(context.Background(), "keyB", "GET"))
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestRateLimiter_Mutating(t *testing.T) {
+	l := NewRateLimiter(RateLimit{RPS: 1, Burst: 1}, &RateLimit{RPS: 1, Burst: 1})
+
+	// reads and writes use separate budget
+	start := time.Now()
+	assert.NoError(t, l.Wait(context.Background(), "key", "GET"))
+	assert.NoError(t, l.Wait(context.Background(), "key", "DELETE"))
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestRateLimiter_ContextCanceled(t *testing.T) {
+	l := NewRateLimiter(RateLimit{RPS: 0.1, Burst: 1}, nil)
+	assert.NoError(t, l.Wait(context.Background(), "key", "GET"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, l.Wait(ctx, "key", "GET"), context.DeadlineExceeded)
+}
+
+func TestRateLimiter_Unlimited(t *testing.T) {
+	l := NewRateLimiter(RateLimit{}, nil)
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, l.Wait(context.Background(), "key", "GET"))
+	}
+}
+
+func TestRateLimiter_API(t *testing.T) {
+	calls := 0
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	})
+	defer s.Close()
+	c.RateLimiter = NewRateLimiter(RateLimit{RPS: 0.1, Burst: 1}, nil)
+
+	resp := c.JSONRequest(context.Background(), RequestConfig{Method: "GET", Path: "/test"})
+	assert.NoError(t, resp.Error)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	resp = c.JSONRequest(ctx, RequestConfig{Method: "GET", Path: "/test"})
+	assert.ErrorIs(t, resp.Error, context.DeadlineExceeded)
+	assert.Equal(t, 1, calls)
+}
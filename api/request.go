@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/url"
 	"strings"
 )
@@ -15,6 +14,23 @@ type RequestConfig struct {
 	Query  url.Values
 	Data   url.Values
 	JSON   map[string]any
+
+	// Retryable marks non-idempotent request (POST, PATCH) as safe to retry.
+	Retryable bool
+
+	// IdempotencyKey is sent in API.IdempotencyHeader header when the provider supports it,
+	// which also makes the request safe to retry.
+	IdempotencyKey string
+
+	// Stream makes Do() decode the response body while reading it
+	// instead of reading it all into memory first, useful for large responses.
+	Stream bool
+
+	// Operation is logical name of the call (e.g. `vpc.ListNetworks`),
+	// Location and ResourceID are the target of the call. They're only used for tracing.
+	Operation  string
+	Location   string
+	ResourceID string
 }
 
 // URL returns full request URL composed from baseURL, Path and Query field.
@@ -27,20 +43,24 @@ func (r RequestConfig) url(baseURL string) string {
 	return fmt.Sprintf("%s?%s", url, qs)
 }
 
-// body returns io.Reader either from Data or Json field
-func (r RequestConfig) body() (io.Reader, error) {
+// contentType returns content type matching the payload, form-encoded when Data is set otherwise json.
+func (r RequestConfig) contentType() string {
+	if r.Data != nil {
+		return "application/x-www-form-urlencoded"
+	}
+	return "application/json"
+}
+
+// body returns encoded payload either from Data or Json field
+func (r RequestConfig) body() ([]byte, error) {
 	if r.Data != nil && r.JSON != nil {
 		return nil, errors.New("data and json can not be set at the same time")
 	}
 	if r.Data != nil {
-		return strings.NewReader(r.Data.Encode()), nil
+		return []byte(r.Data.Encode()), nil
 	}
 	if r.JSON != nil {
-		jsonStr, err := json.Marshal(r.JSON)
-		if err != nil {
-			return nil, err
-		}
-		return strings.NewReader(string(jsonStr)), nil
+		return json.Marshal(r.JSON)
 	}
 
 	// for request that don't have body
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy decides whether a failed request should be sent again and how long to wait before doing so.
+// `attempt` starts from 1 for the first (failed) attempt.
+type RetryPolicy interface {
+	ShouldRetry(attempt int, cfg RequestConfig, err error) (time.Duration, bool)
+}
+
+// Backoff is RetryPolicy with exponential backoff.
+// By default only idempotent methods (GET, PUT, DELETE) are retried,
+// POST and PATCH only retried when RequestConfig.Retryable is set.
+type Backoff struct {
+	// MaxAttempts is total number of attempts including the first one.
+	MaxAttempts int
+	// BaseBackoff is the wait time before the first retry, doubled on each next retry.
+	BaseBackoff time.Duration
+	// MaxBackoff caps the wait time between retries.
+	MaxBackoff time.Duration
+	// Jitter is a fraction (0-1) of the wait time that will be randomized.
+	Jitter float64
+	// RetryableStatus is list of status codes to retry, DefaultRetryableStatus if empty.
+	RetryableStatus []int
+}
+
+// DefaultRetryableStatus are status codes that are retried when Backoff.RetryableStatus is not set.
+var DefaultRetryableStatus = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// DefaultBackoff returns Backoff with sensible defaults.
+func DefaultBackoff() *Backoff {
+	return &Backoff{
+		MaxAttempts: 3,
+		BaseBackoff: 500 * time.Millisecond,
+		MaxBackoff:  10 * time.Second,
+		Jitter:      0.2,
+	}
+}
+
+// ShouldRetry implements RetryPolicy
+func (b *Backoff) ShouldRetry(attempt int, cfg RequestConfig, err error) (time.Duration, bool) {
+	if attempt >= b.MaxAttempts || !isIdempotent(cfg) {
+		return 0, false
+	}
+	// caller gave up, no point to retry
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return 0, false
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		if !b.retryableStatus(e.StatusCode) {
+			return 0, false
+		}
+		if wait, ok := retryAfter(e.Header); ok {
+			return wait, true
+		}
+	}
+	// any other error is a transport error (connection reset, timeout, etc) that worth retrying
+	return b.backoff(attempt), true
+}
+
+// backoff returns exponential wait time for given attempt
+func (b *Backoff) backoff(attempt int) time.Duration {
+	wait := b.BaseBackoff
+	for i := 1; i < attempt; i++ {
+		wait *= 2
+		if b.MaxBackoff > 0 && wait >= b.MaxBackoff {
+			break
+		}
+	}
+	if b.MaxBackoff > 0 && wait > b.MaxBackoff {
+		wait = b.MaxBackoff
+	}
+	if b.Jitter > 0 {
+		wait -= time.Duration(rand.Float64() * b.Jitter * float64(wait))
+	}
+	return wait
+}
+
+func (b *Backoff) retryableStatus(code int) bool {
+	statuses := b.RetryableStatus
+	if len(statuses) == 0 {
+		statuses = DefaultRetryableStatus
+	}
+	for _, s := range statuses {
+		if s == code {
+			return true
+		}
+	}
+	return false
+}
+
+// isIdempotent reports whether request is safe to be sent more than once.
+func isIdempotent(cfg RequestConfig) bool {
+	switch strings.ToUpper(cfg.Method) {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return cfg.Retryable
+}
+
+// retryAfter parses `Retry-After` header which could be either seconds or HTTP date.
+func retryAfter(h http.Header) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		wait := time.Until(t)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// sleep waits for given duration or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetry_IdempotentMethod(t *testing.T) {
+	calls := 0
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("OK"))
+	})
+	defer s.Close()
+	c.RetryPolicy = &Backoff{MaxAttempts: 3, BaseBackoff: time.Millisecond}
+
+	resp := c.JSONRequest(context.Background(), RequestConfig{Method: "GET", Path: "/test"})
+	assert.NoError(t, resp.Error)
+	assert.Equal(t, []byte("OK"), resp.Body)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetry_MaxAttempts(t *testing.T) {
+	calls := 0
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	defer s.Close()
+	c.RetryPolicy = &Backoff{MaxAttempts: 2, BaseBackoff: time.Millisecond}
+
+	resp := c.JSONRequest(context.Background(), RequestConfig{Method: "DELETE", Path: "/test"})
+	assert.True(t, HasStatus(resp.Error, http.StatusBadGateway))
+	assert.Equal(t, 2, calls)
+}
+
+func TestRetry_NonRetryableStatus(t *testing.T) {
+	calls := 0
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	defer s.Close()
+	c.RetryPolicy = &Backoff{MaxAttempts: 3, BaseBackoff: time.Millisecond}
+
+	c.JSONRequest(context.Background(), RequestConfig{Method: "GET", Path: "/test"})
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetry_NonIdempotentMethod(t *testing.T) {
+	var bodies []string
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer s.Close()
+	c.RetryPolicy = &Backoff{MaxAttempts: 3, BaseBackoff: time.Millisecond}
+
+	cfg := RequestConfig{Method: "POST", Path: "/test", Data: url.Values{"name": []string{"test"}}}
+
+	// not retried by default
+	c.FormRequest(context.Background(), cfg)
+	assert.Equal(t, []string{"name=test"}, bodies)
+
+	// retried when caller opted in, resending the same payload
+	bodies = nil
+	cfg.Retryable = true
+	c.FormRequest(context.Background(), cfg)
+	assert.Equal(t, []string{"name=test", "name=test", "name=test"}, bodies)
+}
+
+func TestRetry_RetryAfter(t *testing.T) {
+	calls := 0
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("OK"))
+	})
+	defer s.Close()
+	c.RetryPolicy = &Backoff{MaxAttempts: 2, BaseBackoff: time.Millisecond}
+
+	start := time.Now()
+	resp := c.JSONRequest(context.Background(), RequestConfig{Method: "GET", Path: "/test"})
+	assert.NoError(t, resp.Error)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+}
+
+func TestRetry_ContextCanceled(t *testing.T) {
+	calls := 0
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer s.Close()
+	c.RetryPolicy = &Backoff{MaxAttempts: 5, BaseBackoff: time.Hour}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	resp := c.JSONRequest(ctx, RequestConfig{Method: "GET", Path: "/test"})
+	assert.Error(t, resp.Error)
+	assert.Equal(t, 1, calls)
+}
+
+func TestBackoff_backoff(t *testing.T) {
+	b := &Backoff{BaseBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	assert.Equal(t, time.Second, b.backoff(1))
+	assert.Equal(t, 2*time.Second, b.backoff(2))
+	assert.Equal(t, 4*time.Second, b.backoff(3))
+	assert.Equal(t, 5*time.Second, b.backoff(4))
+	assert.Equal(t, 5*time.Second, b.backoff(50))
+
+	b.Jitter = 0.5
+	for i := 0; i < 10; i++ {
+		wait := b.backoff(1)
+		assert.GreaterOrEqual(t, wait, 500*time.Millisecond)
+		assert.LessOrEqual(t, wait, time.Second)
+	}
+}
+
+func TestBackoff_TransportError(t *testing.T) {
+	b := &Backoff{MaxAttempts: 2, BaseBackoff: time.Millisecond}
+	_, ok := b.ShouldRetry(1, RequestConfig{Method: "GET"}, errors.New("connection reset by peer"))
+	assert.True(t, ok)
+	_, ok = b.ShouldRetry(1, RequestConfig{Method: "GET"}, context.Canceled)
+	assert.False(t, ok)
+}
+
+func TestRetry_IdempotencyKey(t *testing.T) {
+	var keys []string
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(IdempotencyKeyHeader))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer s.Close()
+	c.RetryPolicy = &Backoff{MaxAttempts: 3, BaseBackoff: time.Millisecond}
+	cfg := RequestConfig{Method: "POST", Path: "/test", IdempotencyKey: "key-1"}
+
+	// provider doesn't support idempotency key, not sent nor retried
+	resp := c.JSONRequest(context.Background(), cfg)
+	assert.True(t, HasStatus(resp.Error, http.StatusServiceUnavailable))
+	assert.Equal(t, []string{""}, keys)
+
+	keys = nil
+	c.IdempotencyHeader = IdempotencyKeyHeader
+	resp = c.JSONRequest(context.Background(), cfg)
+	assert.True(t, HasStatus(resp.Error, http.StatusServiceUnavailable))
+	assert.Equal(t, []string{"key-1", "key-1", "key-1"}, keys)
+}
+
+func TestRetry_DecodeError(t *testing.T) {
+	calls := 0
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{"name": `))
+	})
+	defer s.Close()
+	c.RetryPolicy = &Backoff{MaxAttempts: 3, BaseBackoff: time.Millisecond}
+
+	var v map[string]any
+	err := DoInto(context.Background(), c, RequestConfig{Method: "POST", Path: "/test", Retryable: true, Stream: true}, &v)
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
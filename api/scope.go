@@ -0,0 +1,71 @@
+package api
+
+import "fmt"
+
+// Scope is the data center location and billing account a call applies to
+type Scope struct {
+	Location         string
+	BillingAccountID int
+}
+
+// ScopeField is a set of Scope fields
+type ScopeField int
+
+const (
+	// ScopeLocation is Scope.Location
+	ScopeLocation ScopeField = 1 << iota
+	// ScopeBillingAccount is Scope.BillingAccountID
+	ScopeBillingAccount
+)
+
+// CallOption overrides scope of the client for a single call
+type CallOption func(*Scope)
+
+// InLocation makes the call target given data center location instead of the client location
+func InLocation(location string) CallOption {
+	return func(s *Scope) {
+		s.Location = location
+	}
+}
+
+// ForBillingAccount makes the call use given billing account instead of the client billing account
+func ForBillingAccount(id int) CallOption {
+	return func(s *Scope) {
+		s.BillingAccountID = id
+	}
+}
+
+// ResolveScope returns scope of the client with call options applied
+func ResolveScope(client Scope, opts []CallOption) Scope {
+	for _, opt := range opts {
+		opt(&client)
+	}
+	return client
+}
+
+// CheckOptions returns ErrUnsupportedOption when opts override scope fields other than supported,
+// so the options a method doesn't use are rejected rather than ignored.
+func CheckOptions(opts []CallOption, supported ScopeField) error {
+	s := ResolveScope(Scope{}, opts)
+	if s.Location != "" && supported&ScopeLocation == 0 {
+		return fmt.Errorf("%w: location %q", ErrUnsupportedOption, s.Location)
+	}
+	if s.BillingAccountID != 0 && supported&ScopeBillingAccount == 0 {
+		return fmt.Errorf("%w: billing account %d", ErrUnsupportedOption, s.BillingAccountID)
+	}
+	return nil
+}
+
+// Resolve returns scope of a call made by client with scope s, which is s unless overridden by opts.
+// Options overriding fields other than supported are rejected with ErrUnsupportedOption,
+// and ErrMissingLocation is returned if the call is location-scoped but neither s nor opts set the location.
+func (s Scope) Resolve(opts []CallOption, supported ScopeField) (Scope, error) {
+	if err := CheckOptions(opts, supported); err != nil {
+		return Scope{}, err
+	}
+	s = ResolveScope(s, opts)
+	if supported&ScopeLocation != 0 && s.Location == "" {
+		return Scope{}, ErrMissingLocation
+	}
+	return s, nil
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveScope(t *testing.T) {
+	client := Scope{Location: "jkt01", BillingAccountID: 1}
+	assert.Equal(t, client, ResolveScope(client, nil))
+	assert.Equal(t, Scope{Location: "sgp01", BillingAccountID: 2},
+		ResolveScope(client, []CallOption{InLocation("sgp01"), ForBillingAccount(2)}))
+}
+
+func TestCheckOptions(t *testing.T) {
+	assert.NoError(t, CheckOptions(nil, 0))
+	assert.NoError(t, CheckOptions([]CallOption{InLocation("sgp01")}, ScopeLocation))
+	assert.NoError(t, CheckOptions([]CallOption{InLocation("sgp01"), ForBillingAccount(2)}, ScopeLocation|ScopeBillingAccount))
+	assert.ErrorIs(t, CheckOptions([]CallOption{ForBillingAccount(2)}, ScopeLocation), ErrUnsupportedOption)
+	assert.ErrorIs(t, CheckOptions([]CallOption{InLocation("sgp01")}, ScopeBillingAccount), ErrUnsupportedOption)
+}
+
+func TestScope_Resolve(t *testing.T) {
+	client := Scope{Location: "jkt01", BillingAccountID: 1}
+	s, err := client.Resolve([]CallOption{InLocation("sgp01")}, ScopeLocation)
+	assert.NoError(t, err)
+	assert.Equal(t, Scope{Location: "sgp01", BillingAccountID: 1}, s)
+
+	_, err = client.Resolve([]CallOption{ForBillingAccount(2)}, ScopeLocation)
+	assert.ErrorIs(t, err, ErrUnsupportedOption)
+
+	_, err = Scope{}.Resolve(nil, ScopeLocation|ScopeBillingAccount)
+	assert.ErrorIs(t, err, ErrMissingLocation)
+
+	s, err = Scope{}.Resolve([]CallOption{ForBillingAccount(2)}, ScopeBillingAccount)
+	assert.NoError(t, err)
+	assert.Equal(t, Scope{BillingAccountID: 2}, s)
+}
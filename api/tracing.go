@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/ekaputra07/warren-go/api"
+
+// Tracing enables OpenTelemetry span for every API call, including all of its retries.
+// Span is named after RequestConfig.Operation (e.g. `vpc.ListNetworks`) and trace context
+// is propagated to the server in request headers.
+type Tracing struct {
+	// TracerProvider used to create spans, otel.GetTracerProvider() if nil.
+	TracerProvider trace.TracerProvider
+	// Propagator used to inject trace context into request headers, otel.GetTextMapPropagator() if nil.
+	Propagator propagation.TextMapPropagator
+}
+
+// Span attribute keys
+const (
+	AttrLocation   = attribute.Key("warren.location")
+	AttrResourceID = attribute.Key("warren.resource_id")
+	AttrRetryCount = attribute.Key("warren.retry_count")
+	AttrMethod     = attribute.Key("http.request.method")
+	AttrPath       = attribute.Key("url.path")
+	AttrStatusCode = attribute.Key("http.response.status_code")
+)
+
+// startSpan starts a span for cfg, it returns nil span when tracing is disabled.
+func (t *Tracing) startSpan(ctx context.Context, cfg RequestConfig) (context.Context, trace.Span) {
+	if t == nil {
+		return ctx, nil
+	}
+	tp := t.TracerProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	attrs := []attribute.KeyValue{
+		AttrMethod.String(strings.ToUpper(cfg.Method)),
+		AttrPath.String(cfg.Path),
+	}
+	if cfg.Location != "" {
+		attrs = append(attrs, AttrLocation.String(cfg.Location))
+	}
+	if cfg.ResourceID != "" {
+		attrs = append(attrs, AttrResourceID.String(cfg.ResourceID))
+	}
+	return tp.Tracer(tracerName).Start(ctx, cfg.operation(),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// inject propagates trace context from ctx into request headers.
+func (t *Tracing) inject(ctx context.Context, req *Request) {
+	if t == nil {
+		return
+	}
+	p := t.Propagator
+	if p == nil {
+		p = otel.GetTextMapPropagator()
+	}
+	p.Inject(ctx, propagation.HeaderCarrier(req.HTTP.Header))
+}
+
+// endSpan records the outcome of the call and ends the span.
+func endSpan(span trace.Span, attempts, status int, err error) {
+	if span == nil {
+		return
+	}
+	span.SetAttributes(AttrRetryCount.Int(attempts - 1))
+	if status != 0 {
+		span.SetAttributes(AttrStatusCode.Int(status))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// operation returns logical operation name used as span name.
+func (r RequestConfig) operation() string {
+	if r.Operation != "" {
+		return r.Operation
+	}
+	return fmt.Sprintf("%s %s", strings.ToUpper(r.Method), r.Path)
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestTracing() (*Tracing, *tracetest.InMemoryExporter) {
+	exp := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+	return &Tracing{TracerProvider: tp, Propagator: propagation.TraceContext{}}, exp
+}
+
+func attrs(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	m := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestTracing(t *testing.T) {
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, r.Header.Get("traceparent"))
+		w.Write([]byte("OK"))
+	})
+	defer s.Close()
+
+	tracing, exp := newTestTracing()
+	c.Tracing = tracing
+
+	resp := c.JSONRequest(context.Background(), RequestConfig{
+		Method:     "GET",
+		Path:       "/v1/jkt01/network/network/abc",
+		Operation:  "vpc.GetNetwork",
+		Location:   "jkt01",
+		ResourceID: "abc",
+	})
+	assert.NoError(t, resp.Error)
+
+	spans := exp.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "vpc.GetNetwork", spans[0].Name)
+
+	a := attrs(spans[0])
+	assert.Equal(t, "jkt01", a[AttrLocation].AsString())
+	assert.Equal(t, "abc", a[AttrResourceID].AsString())
+	assert.Equal(t, "GET", a[AttrMethod].AsString())
+	assert.Equal(t, int64(200), a[AttrStatusCode].AsInt64())
+	assert.Equal(t, int64(0), a[AttrRetryCount].AsInt64())
+}
+
+func TestTracing_RetriesAndError(t *testing.T) {
+	c, s := MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer s.Close()
+
+	tracing, exp := newTestTracing()
+	c.Tracing = tracing
+	c.RetryPolicy = &Backoff{MaxAttempts: 3, BaseBackoff: time.Millisecond}
+
+	resp := c.JSONRequest(context.Background(), RequestConfig{Method: "DELETE", Path: "/test"})
+	assert.Error(t, resp.Error)
+
+	spans := exp.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "DELETE /test", spans[0].Name)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+
+	a := attrs(spans[0])
+	assert.Equal(t, int64(503), a[AttrStatusCode].AsInt64())
+	assert.Equal(t, int64(2), a[AttrRetryCount].AsInt64())
+}
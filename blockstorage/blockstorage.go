@@ -2,14 +2,12 @@ package blockstorage
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/url"
 	"strconv"
 
 	"github.com/ekaputra07/warren-go/api"
 	"github.com/google/uuid"
-	"github.com/gorilla/schema"
 )
 
 func NewClient(client *api.API) *Client {
@@ -18,103 +16,200 @@ func NewClient(client *api.API) *Client {
 	}
 }
 
+// WithBillingAccount returns copy of the client scoped to given billing account
+func (c *Client) WithBillingAccount(id int) *Client {
+	cc := *c
+	cc.BillingAccountID = id
+	return &cc
+}
+
+// billingAccount returns billing account of the call, which is the client billing account unless overridden.
+// Block storage isn't location-scoped, so location can't be overridden.
+func (c *Client) billingAccount(opts []api.CallOption) (int, error) {
+	s, err := api.Scope{BillingAccountID: c.BillingAccountID}.Resolve(opts, api.ScopeBillingAccount)
+	return s.BillingAccountID, err
+}
+
 // ListDisks https://api.warren.io/#list-disks
-func (c *Client) ListDisks(ctx context.Context) ([]Disk, error) {
-	rc := api.RequestConfig{
-		Method: "GET",
-		Path:   "/v1/storage/disks",
-	}
-	resp := c.API.FormRequest(ctx, rc)
-	if resp.Error != nil {
-		return nil, resp.Error
-	}
-	var disks []Disk
-	if err := json.Unmarshal(resp.Body, &disks); err != nil {
+func (c *Client) ListDisks(ctx context.Context, opts ...api.CallOption) ([]Disk, error) {
+	if err := api.CheckOptions(opts, 0); err != nil {
 		return nil, err
 	}
-	return disks, nil
+	rc := api.RequestConfig{
+		Method:    "GET",
+		Path:      "/v1/storage/disks",
+		Operation: "blockstorage.ListDisks",
+	}
+	return api.Do[[]Disk](ctx, c.API, rc)
 }
 
 // CreateDisk https://api.warren.io/#create-disk
-func (c *Client) CreateDisk(ctx context.Context, disk *Disk) error {
-	enc := schema.NewEncoder()
-	d := url.Values{}
-	if err := enc.Encode(disk, d); err != nil {
+// The disk is billed to the client billing account unless disk.BillingAccountID is set.
+func (c *Client) CreateDisk(ctx context.Context, disk *Disk, opts ...api.CallOption) error {
+	return c.createDisk(ctx, disk, "", opts)
+}
+
+func (c *Client) createDisk(ctx context.Context, disk *Disk, idempotencyKey string, opts []api.CallOption) error {
+	billingAccountID, err := c.billingAccount(opts)
+	if err != nil {
 		return err
 	}
+	if disk.BillingAccountID != 0 {
+		billingAccountID = disk.BillingAccountID
+	}
+	d := url.Values{
+		"size_gb": []string{strconv.Itoa(disk.SizeGB)},
+	}
+	if billingAccountID != 0 {
+		d.Set("billing_account_id", strconv.Itoa(billingAccountID))
+	}
+	if disk.SourceImageType != "" {
+		d.Set("source_image_type", string(disk.SourceImageType))
+	}
+	if disk.SourceImage != "" {
+		d.Set("source_image", disk.SourceImage)
+	}
 
 	rc := api.RequestConfig{
-		Method: "POST",
-		Path:   "/v1/storage/disks",
-		Data:   d,
+		Method:         "POST",
+		Path:           "/v1/storage/disks",
+		Data:           d,
+		IdempotencyKey: idempotencyKey,
+		Operation:      "blockstorage.CreateDisk",
 	}
-	resp := c.API.FormRequest(ctx, rc)
-	if resp.Error != nil {
-		return resp.Error
-	}
-	return json.Unmarshal(resp.Body, disk)
+	return api.DoInto(ctx, c.API, rc, disk)
 }
 
-// GetDisk https://api.warren.io/#get-disk
-func (c *Client) GetDisk(ctx context.Context, diskID uuid.UUID) (Disk, error) {
-	rc := api.RequestConfig{
-		Method: "GET",
-		Path:   fmt.Sprintf("/v1/storage/disks/%s", diskID),
+// EnsureDisk creates disk exactly once even if the create request is retried, see api.NewIdempotencyKey.
+// Disks have no natural key, so after a failure the created disk is the single new disk matching the spec.
+func (c *Client) EnsureDisk(ctx context.Context, disk Disk, opts ...api.CallOption) (Disk, bool, error) {
+	billingAccountID, err := c.billingAccount(opts)
+	if err != nil {
+		return Disk{}, false, err
+	}
+	if disk.BillingAccountID == 0 {
+		disk.BillingAccountID = billingAccountID
+	}
+
+	before, err := c.ListDisks(ctx)
+	if err != nil {
+		return Disk{}, false, err
+	}
+	existing := map[uuid.UUID]bool{}
+	for _, d := range before {
+		existing[d.UUID] = true
+	}
+
+	spec := disk
+	err = c.createDisk(ctx, &disk, api.NewIdempotencyKey(), nil)
+	if err == nil {
+		return disk, true, nil
+	}
+	if !api.MayHaveSucceeded(err) {
+		return Disk{}, false, err
+	}
+	after, lerr := c.ListDisks(ctx)
+	if lerr != nil {
+		return Disk{}, false, err
 	}
-	resp := c.API.FormRequest(ctx, rc)
-	if resp.Error != nil {
-		return Disk{}, resp.Error
+	var created []Disk
+	for _, d := range after {
+		if !existing[d.UUID] && matchesSpec(d, spec) {
+			created = append(created, d)
+		}
 	}
-	var disk Disk
-	if err := json.Unmarshal(resp.Body, &disk); err != nil {
+	// more than one match means others created the same disk concurrently, it can't be told which one is ours
+	if len(created) != 1 {
+		return Disk{}, false, err
+	}
+	return created[0], true, nil
+}
+
+// matchesSpec reports whether d could have been created from spec
+func matchesSpec(d, spec Disk) bool {
+	return d.SizeGB == spec.SizeGB &&
+		(spec.BillingAccountID == 0 || d.BillingAccountID == spec.BillingAccountID) &&
+		(spec.SourceImageType == "" || d.SourceImageType == spec.SourceImageType) &&
+		d.SourceImage == spec.SourceImage
+}
+
+// GetDisk https://api.warren.io/#get-disk
+func (c *Client) GetDisk(ctx context.Context, diskID uuid.UUID, opts ...api.CallOption) (Disk, error) {
+	if err := api.CheckOptions(opts, 0); err != nil {
 		return Disk{}, err
 	}
-	return disk, nil
+	rc := api.RequestConfig{
+		Method:     "GET",
+		Path:       fmt.Sprintf("/v1/storage/disks/%s", diskID),
+		Operation:  "blockstorage.GetDisk",
+		ResourceID: diskID.String(),
+	}
+	return api.Do[Disk](ctx, c.API, rc)
 }
 
 // DeleteDisk https://api.warren.io/#delete-disk
-func (c *Client) DeleteDisk(ctx context.Context, diskID uuid.UUID) error {
+func (c *Client) DeleteDisk(ctx context.Context, diskID uuid.UUID, opts ...api.CallOption) error {
+	if err := api.CheckOptions(opts, 0); err != nil {
+		return err
+	}
 	rc := api.RequestConfig{
-		Method: "DELETE",
-		Path:   fmt.Sprintf("/v1/storage/disks/%s", diskID),
+		Method:     "DELETE",
+		Path:       fmt.Sprintf("/v1/storage/disks/%s", diskID),
+		Operation:  "blockstorage.DeleteDisk",
+		ResourceID: diskID.String(),
 	}
-	return c.API.FormRequest(ctx, rc).Error
+	return api.DoNoContent(ctx, c.API, rc)
 }
 
 // AttachDiskToVM https://api.warren.io/#attach-disk
-func (c *Client) AttachDiskToVM(ctx context.Context, diskID, vmID uuid.UUID) error {
+func (c *Client) AttachDiskToVM(ctx context.Context, diskID, vmID uuid.UUID, opts ...api.CallOption) error {
+	if err := api.CheckOptions(opts, 0); err != nil {
+		return err
+	}
 	d := url.Values{
 		"uuid":         []string{vmID.String()},
 		"storage_uuid": []string{diskID.String()},
 	}
 	rc := api.RequestConfig{
-		Method: "POST",
-		Path:   "/v1/user-resource/vm/storage/attach",
-		Data:   d,
+		Method:     "POST",
+		Path:       "/v1/user-resource/vm/storage/attach",
+		Data:       d,
+		Operation:  "blockstorage.AttachDiskToVM",
+		ResourceID: diskID.String(),
 	}
-	return c.API.FormRequest(ctx, rc).Error
+	return api.DoNoContent(ctx, c.API, rc)
 }
 
 // DetachDiskFromVM https://api.warren.io/#detach-disk
-func (c *Client) DetachDiskFromVM(ctx context.Context, diskID, vmID uuid.UUID) error {
+func (c *Client) DetachDiskFromVM(ctx context.Context, diskID, vmID uuid.UUID, opts ...api.CallOption) error {
+	if err := api.CheckOptions(opts, 0); err != nil {
+		return err
+	}
 	d := url.Values{
 		"uuid":         []string{vmID.String()},
 		"storage_uuid": []string{diskID.String()},
 	}
 	rc := api.RequestConfig{
-		Method: "POST",
-		Path:   "/v1/user-resource/vm/storage/detach",
-		Data:   d,
+		Method:     "POST",
+		Path:       "/v1/user-resource/vm/storage/detach",
+		Data:       d,
+		Operation:  "blockstorage.DetachDiskFromVM",
+		ResourceID: diskID.String(),
 	}
-	return c.API.FormRequest(ctx, rc).Error
+	return api.DoNoContent(ctx, c.API, rc)
 }
 
 // UpdateDiskBillingAccount https://api.warren.io/#modify-disk-info
-func (c *Client) UpdateDiskBillingAccount(ctx context.Context, diskID uuid.UUID, billingAccountID int) error {
+func (c *Client) UpdateDiskBillingAccount(ctx context.Context, diskID uuid.UUID, billingAccountID int, opts ...api.CallOption) error {
+	if err := api.CheckOptions(opts, 0); err != nil {
+		return err
+	}
 	rc := api.RequestConfig{
-		Method: "PATCH",
-		Path:   fmt.Sprintf("/v1/storage/disks/%s", diskID),
-		Data:   url.Values{"billing_account_id": []string{strconv.Itoa(billingAccountID)}},
+		Method:     "PATCH",
+		Path:       fmt.Sprintf("/v1/storage/disks/%s", diskID),
+		Data:       url.Values{"billing_account_id": []string{strconv.Itoa(billingAccountID)}},
+		Operation:  "blockstorage.UpdateDiskBillingAccount",
+		ResourceID: diskID.String(),
 	}
-	return c.API.FormRequest(ctx, rc).Error
+	return api.DoNoContent(ctx, c.API, rc)
 }
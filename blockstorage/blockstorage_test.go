@@ -2,12 +2,15 @@ package blockstorage
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/ekaputra07/warren-go/api"
+	"github.com/ekaputra07/warren-go/wait"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
@@ -119,3 +122,83 @@ func TestUpdateBucketBillingAccount(t *testing.T) {
 	bs := Client{API: a}
 	bs.UpdateDiskBillingAccount(context.Background(), id, 123)
 }
+
+func TestGetDisk_NotFound(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	defer s.Close()
+
+	bs := Client{API: a}
+	_, err := bs.GetDisk(context.Background(), uuid.New())
+	assert.True(t, api.IsNotFound(err))
+}
+
+func TestEnsureDisk_IdempotencyKey(t *testing.T) {
+	var created bool
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			if created {
+				w.Write([]byte(`[{"uuid": "4e5eadd3-8b11-4c34-812a-2cf97120b628", "size_gb": 10}]`))
+				return
+			}
+			w.Write([]byte(`[]`))
+			return
+		}
+		// applied, but the response is lost
+		assert.NotEmpty(t, r.Header.Get(api.IdempotencyKeyHeader))
+		created = true
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	defer s.Close()
+	a.IdempotencyHeader = api.IdempotencyKeyHeader
+
+	bs := Client{API: a}
+	d, ok, err := bs.EnsureDisk(context.Background(), Disk{SizeGB: 10})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "4e5eadd3-8b11-4c34-812a-2cf97120b628", d.UUID.String())
+}
+
+func TestCreateDisk_BillingAccount(t *testing.T) {
+	var billed []string
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		billed = append(billed, r.Form.Get("billing_account_id"))
+	})
+	defer s.Close()
+
+	bs := NewClient(a).WithBillingAccount(123)
+	assert.NoError(t, bs.CreateDisk(context.Background(), &Disk{SizeGB: 10}))
+	assert.NoError(t, bs.CreateDisk(context.Background(), &Disk{SizeGB: 10}, api.ForBillingAccount(456)))
+	assert.NoError(t, bs.CreateDisk(context.Background(), &Disk{SizeGB: 10, BillingAccountID: 789}))
+	assert.Equal(t, []string{"123", "456", "789"}, billed)
+	assert.Equal(t, 0, NewClient(a).BillingAccountID)
+
+	// neither location nor billing account applies to deleting
+	assert.ErrorIs(t, bs.DeleteDisk(context.Background(), uuid.New(), api.ForBillingAccount(456)), api.ErrUnsupportedOption)
+	assert.ErrorIs(t, bs.CreateDisk(context.Background(), &Disk{SizeGB: 10}, api.InLocation("jkt01")), api.ErrUnsupportedOption)
+	assert.Len(t, billed, 3)
+}
+
+func TestWaitForDiskStatus(t *testing.T) {
+	id := uuid.New()
+	var polls int
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, fmt.Sprintf("/v1/storage/disks/%s", id), r.RequestURI)
+		polls++
+		status := "Detached"
+		if polls == 3 {
+			status = "Attached"
+		}
+		json.NewEncoder(w).Encode(Disk{UUID: id, Status: status})
+	})
+	defer s.Close()
+
+	var states []string
+	cfg := wait.Config{Interval: time.Millisecond, OnProgress: func(p wait.Progress) { states = append(states, p.State) }}
+	d, err := WaitForDiskStatus(context.Background(), NewClient(a), id, "Attached", cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "Attached", d.Status)
+	assert.Equal(t, []string{"Detached", "Detached", "Attached"}, states)
+}
@@ -0,0 +1,64 @@
+package blockstorage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ekaputra07/warren-go/internal/golden"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var goldenDisk = Disk{
+	UUID:   uuid.MustParse("8b1d9e5a-4f2c-4c1e-9a53-0c2d6f1e7b21"),
+	Status: "Attached",
+	Snapshots: []Snapshot{{
+		UUID:      uuid.MustParse("3f6c2a71-95d4-4c8e-b0a2-7d9e1f4a6c35"),
+		SizeGB:    20,
+		CreatedAt: "2023-05-02 08:15:43",
+		DiskUUID:  uuid.MustParse("8b1d9e5a-4f2c-4c1e-9a53-0c2d6f1e7b21"),
+	}},
+	UserID:           1234,
+	BillingAccountID: 5678,
+	SizeGB:           20,
+	SourceImageType:  ImageTypeOSBase,
+	SourceImage:      "ubuntu_20.04",
+	CreatedAt:        "2023-05-01 10:20:30",
+	UpdatedAt:        "2023-05-02 08:15:43",
+}
+
+func TestContract_GetDisk(t *testing.T) {
+	a, fixture := golden.Server(t, "disk.json")
+
+	d, err := NewClient(a).GetDisk(context.Background(), goldenDisk.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, goldenDisk, d)
+	golden.AssertRoundTrip(t, fixture, d)
+}
+
+func TestContract_ListDisks(t *testing.T) {
+	a, fixture := golden.Server(t, "disks.json")
+
+	disks, err := NewClient(a).ListDisks(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []Disk{goldenDisk, {
+		UUID:             uuid.MustParse("c4e7a2d9-1b3f-4e6a-8d5c-2f9b0a7e3d14"),
+		Status:           "Detached",
+		Snapshots:        []Snapshot{},
+		UserID:           1234,
+		BillingAccountID: 5678,
+		SizeGB:           50,
+		SourceImageType:  ImageTypeEmpty,
+		CreatedAt:        "2023-06-11 12:00:00",
+		UpdatedAt:        "2023-06-11 12:00:00",
+	}}, disks)
+	golden.AssertRoundTrip(t, fixture, disks)
+}
+
+func TestContract_CreateDisk(t *testing.T) {
+	a, _ := golden.Server(t, "disk.json")
+
+	d := Disk{SizeGB: 20, SourceImageType: ImageTypeOSBase, SourceImage: "ubuntu_20.04"}
+	assert.NoError(t, NewClient(a).CreateDisk(context.Background(), &d))
+	assert.Equal(t, goldenDisk, d)
+}
@@ -1,12 +1,34 @@
 package blockstorage
 
 import (
+	"context"
+
 	"github.com/ekaputra07/warren-go/api"
 	"github.com/google/uuid"
 )
 
+// Service is implemented by Client, use it in place of *Client to be able to mock the API.
+// Every method accepts call options to override scope of the client for that single call,
+// options that don't apply to the method are rejected with api.ErrUnsupportedOption.
+type Service interface {
+	ListDisks(ctx context.Context, opts ...api.CallOption) ([]Disk, error)
+	CreateDisk(ctx context.Context, disk *Disk, opts ...api.CallOption) error
+	EnsureDisk(ctx context.Context, disk Disk, opts ...api.CallOption) (Disk, bool, error)
+	GetDisk(ctx context.Context, diskID uuid.UUID, opts ...api.CallOption) (Disk, error)
+	DeleteDisk(ctx context.Context, diskID uuid.UUID, opts ...api.CallOption) error
+	AttachDiskToVM(ctx context.Context, diskID, vmID uuid.UUID, opts ...api.CallOption) error
+	DetachDiskFromVM(ctx context.Context, diskID, vmID uuid.UUID, opts ...api.CallOption) error
+	UpdateDiskBillingAccount(ctx context.Context, diskID uuid.UUID, billingAccountID int, opts ...api.CallOption) error
+}
+
+var _ Service = (*Client)(nil)
+
+// Client is block storage client, it's safe for concurrent use.
+// Use WithBillingAccount or api.ForBillingAccount call option instead of modifying BillingAccountID of a shared client.
+// Billing account is used by the methods creating disk only.
 type Client struct {
-	API *api.API
+	BillingAccountID int
+	API              *api.API
 }
 
 type SourceImageType string
@@ -19,21 +41,21 @@ const (
 )
 
 type Snapshot struct {
-	UUID      uuid.UUID `schema:"uuid"`
-	SizeGB    int       `schema:"sizeGb"`
-	CreatedAt string    `schema:"created_at"`
-	DiskUUID  uuid.UUID `schema:"disk_uuid"`
+	UUID      uuid.UUID `json:"uuid"`
+	SizeGB    int       `json:"sizeGb"`
+	CreatedAt string    `json:"created_at"`
+	DiskUUID  uuid.UUID `json:"disk_uuid"`
 }
 
 type Disk struct {
-	UUID             uuid.UUID       `schema:"uuid"`
-	Status           string          `schema:"status"`
-	Snapshots        []Snapshot      `schema:"snapshots"`
-	UserID           int             `schema:"user_id"`
-	BillingAccountID int             `schema:"billing_account_id"`
-	SizeGB           int             `schema:"size_gb"`
-	SourceImageType  SourceImageType `schema:"source_image_type"`
-	SourceImage      string          `schema:"source_image"`
-	CreatedAt        string          `schema:"created_at"`
-	UpdatedAt        string          `schema:"updated_at"`
+	UUID             uuid.UUID       `json:"uuid"`
+	Status           string          `json:"status"`
+	Snapshots        []Snapshot      `json:"snapshots"`
+	UserID           int             `json:"user_id"`
+	BillingAccountID int             `json:"billing_account_id"`
+	SizeGB           int             `json:"size_gb"`
+	SourceImageType  SourceImageType `json:"source_image_type"`
+	SourceImage      string          `json:"source_image"`
+	CreatedAt        string          `json:"created_at"`
+	UpdatedAt        string          `json:"updated_at"`
 }
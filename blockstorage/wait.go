@@ -0,0 +1,19 @@
+package blockstorage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ekaputra07/warren-go/wait"
+	"github.com/google/uuid"
+)
+
+// WaitForDiskStatus polls the disk until it has given status (e.g. "Attached"), see wait.Until
+func WaitForDiskStatus(ctx context.Context, s Service, id uuid.UUID, status string, cfg wait.Config) (Disk, error) {
+	return wait.Until(ctx, cfg, wait.Condition[Disk]{
+		Description: fmt.Sprintf("disk %s to be %s", id, status),
+		Poll:        func(ctx context.Context) (Disk, error) { return s.GetDisk(ctx, id) },
+		Done:        func(d Disk) bool { return d.Status == status },
+		State:       func(d Disk) string { return d.Status },
+	})
+}
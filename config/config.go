@@ -0,0 +1,213 @@
+// Package config loads Warren API settings from a profiles file, so a single app can manage
+// resources across multiple hosting providers and locations.
+//
+// Example of config file (~/.config/warren/config.yaml):
+//
+//	default_profile: idcloudhost
+//	profiles:
+//	  idcloudhost:
+//	    base_url: https://api.idcloudhost.com
+//	    api_key: secret123
+//	    location: jkt01
+//	    billing_account_id: 123
+//	  other:
+//	    base_url: https://api.other.com
+//	    api_key_command: pass show warren/other
+//	    location: sgp01
+//
+// Environment variables override values from the file:
+// WARREN_API_BASE_URL, WARREN_API_KEY, WARREN_LOCATION and WARREN_BILLING_ACCOUNT_ID.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ekaputra07/warren-go"
+	"github.com/ekaputra07/warren-go/api"
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables read by Load in addition to api.BaseURLEnvKey and api.APIKeyEnvKey
+const (
+	// ConfigEnvKey is path of the config file
+	ConfigEnvKey string = "WARREN_CONFIG"
+	// ProfileEnvKey is name of the profile to load
+	ProfileEnvKey string = "WARREN_PROFILE"
+	// LocationEnvKey overrides location of the profile
+	LocationEnvKey string = "WARREN_LOCATION"
+	// BillingAccountEnvKey overrides billing account of the profile
+	BillingAccountEnvKey string = "WARREN_BILLING_ACCOUNT_ID"
+)
+
+const defaultProfileName string = "default"
+
+// File represents content of the config file
+type File struct {
+	DefaultProfile string             `yaml:"default_profile"`
+	Profiles       map[string]Profile `yaml:"profiles"`
+}
+
+// Profile holds settings to access a single provider
+type Profile struct {
+	Name    string `yaml:"-"`
+	BaseURL string `yaml:"base_url"`
+
+	// APIKey is taken from the first non-empty of APIKey, APIKeyFile content or APIKeyCommand output.
+	APIKey        string `yaml:"api_key"`
+	APIKeyFile    string `yaml:"api_key_file"`
+	APIKeyCommand string `yaml:"api_key_command"`
+
+	// Location and BillingAccountID are defaults for resources that need them.
+	Location         string `yaml:"location"`
+	BillingAccountID int    `yaml:"billing_account_id"`
+}
+
+// DefaultPath returns config file path, which is WARREN_CONFIG if set otherwise `<user config dir>/warren/config.yaml`.
+func DefaultPath() (string, error) {
+	if p := os.Getenv(ConfigEnvKey); p != "" {
+		return p, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "warren", "config.yaml"), nil
+}
+
+// LoadFile reads and parses config file in given path
+func LoadFile(path string) (*File, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f File
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// Profile returns profile with given name with environment variables applied.
+// When name is empty, it's taken from WARREN_PROFILE, then `default_profile` and finally "default".
+func (f *File) Profile(name string) (Profile, error) {
+	explicit := name != "" || os.Getenv(ProfileEnvKey) != ""
+	if name == "" {
+		name = os.Getenv(ProfileEnvKey)
+	}
+	if name == "" {
+		name = f.DefaultProfile
+	}
+	if name == "" {
+		name = defaultProfileName
+	}
+
+	p, ok := f.Profiles[name]
+	if !ok && (explicit || f.DefaultProfile != "") {
+		return Profile{}, fmt.Errorf("profile %q not found", name)
+	}
+	p.Name = name
+	return p.withEnv()
+}
+
+// Load loads profile with given name from config file in DefaultPath().
+// A missing config file is not an error as long as no profile explicitly requested,
+// in that case the profile is built only from environment variables.
+func Load(name string) (Profile, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return Profile{}, err
+	}
+	f, err := LoadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		f = &File{}
+	} else if err != nil {
+		return Profile{}, err
+	}
+	return f.Profile(name)
+}
+
+// withEnv returns copy of p with values overridden by environment variables.
+func (p Profile) withEnv() (Profile, error) {
+	if v := os.Getenv(api.BaseURLEnvKey); v != "" {
+		p.BaseURL = v
+	}
+	if v := os.Getenv(api.APIKeyEnvKey); v != "" {
+		p.APIKey = v
+	}
+	if v := os.Getenv(LocationEnvKey); v != "" {
+		p.Location = v
+	}
+	if v := os.Getenv(BillingAccountEnvKey); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return p, fmt.Errorf("invalid %s: %w", BillingAccountEnvKey, err)
+		}
+		p.BillingAccountID = id
+	}
+	return p, nil
+}
+
+// ResolveAPIKey returns the API key either from APIKey, APIKeyFile or APIKeyCommand.
+func (p Profile) ResolveAPIKey() (string, error) {
+	if p.APIKey != "" {
+		return p.APIKey, nil
+	}
+	if p.APIKeyFile != "" {
+		b, err := os.ReadFile(expandHome(p.APIKeyFile))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+	if p.APIKeyCommand != "" {
+		var stderr bytes.Buffer
+		cmd := exec.Command("sh", "-c", p.APIKeyCommand)
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("api_key_command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+	return "", fmt.Errorf("profile %q has no API key", p.Name)
+}
+
+// API returns API client for the profile
+func (p Profile) API() (*api.API, error) {
+	if p.BaseURL == "" {
+		return nil, fmt.Errorf("profile %q has no base URL", p.Name)
+	}
+	key, err := p.ResolveAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	return api.New(p.BaseURL, key), nil
+}
+
+// Warren returns Warren client for the profile with its default location and billing account.
+func (p Profile) Warren() (*warren.Warren, error) {
+	a, err := p.API()
+	if err != nil {
+		return nil, err
+	}
+	return warren.InitWithScope(a, api.Scope{Location: p.Location, BillingAccountID: p.BillingAccountID}), nil
+}
+
+// expandHome replaces leading `~/` with user home directory
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ekaputra07/warren-go/api"
+	"github.com/ekaputra07/warren-go/ip"
+	"github.com/ekaputra07/warren-go/objectstorage"
+	"github.com/ekaputra07/warren-go/vm"
+	"github.com/ekaputra07/warren-go/vpc"
+	"github.com/stretchr/testify/assert"
+)
+
+const testConfig = `
+default_profile: a
+profiles:
+  a:
+    base_url: https://api.a.com
+    api_key: keyA
+    location: jkt01
+    billing_account_id: 123
+  b:
+    base_url: https://api.b.com
+    api_key_command: echo keyB
+    location: sgp01
+  c:
+    base_url: https://api.c.com
+    api_key_file: %s
+`
+
+func writeConfig(t *testing.T) string {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key")
+	assert.NoError(t, os.WriteFile(keyFile, []byte("keyC\n"), 0600))
+
+	path := filepath.Join(dir, "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf(testConfig, keyFile)), 0600))
+	return path
+}
+
+func clearEnv(t *testing.T) {
+	for _, k := range []string{ProfileEnvKey, api.BaseURLEnvKey, api.APIKeyEnvKey, LocationEnvKey, BillingAccountEnvKey} {
+		t.Setenv(k, "")
+	}
+}
+
+func TestProfile(t *testing.T) {
+	clearEnv(t)
+	f, err := LoadFile(writeConfig(t))
+	assert.NoError(t, err)
+
+	// default profile
+	p, err := f.Profile("")
+	assert.NoError(t, err)
+	assert.Equal(t, Profile{
+		Name:             "a",
+		BaseURL:          "https://api.a.com",
+		APIKey:           "keyA",
+		Location:         "jkt01",
+		BillingAccountID: 123,
+	}, p)
+
+	// by name
+	p, err = f.Profile("b")
+	assert.NoError(t, err)
+	assert.Equal(t, "sgp01", p.Location)
+
+	// by env
+	t.Setenv(ProfileEnvKey, "c")
+	p, err = f.Profile("")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.c.com", p.BaseURL)
+
+	// not found
+	_, err = f.Profile("x")
+	assert.Error(t, err)
+}
+
+func TestProfile_EnvOverride(t *testing.T) {
+	clearEnv(t)
+	f, err := LoadFile(writeConfig(t))
+	assert.NoError(t, err)
+
+	t.Setenv(api.BaseURLEnvKey, "https://api.env.com")
+	t.Setenv(api.APIKeyEnvKey, "keyEnv")
+	t.Setenv(LocationEnvKey, "sgp01")
+	t.Setenv(BillingAccountEnvKey, "456")
+
+	p, err := f.Profile("a")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.env.com", p.BaseURL)
+	assert.Equal(t, "keyEnv", p.APIKey)
+	assert.Equal(t, "sgp01", p.Location)
+	assert.Equal(t, 456, p.BillingAccountID)
+
+	t.Setenv(BillingAccountEnvKey, "abc")
+	_, err = f.Profile("a")
+	assert.Error(t, err)
+}
+
+func TestResolveAPIKey(t *testing.T) {
+	clearEnv(t)
+	f, err := LoadFile(writeConfig(t))
+	assert.NoError(t, err)
+
+	for name, key := range map[string]string{"a": "keyA", "b": "keyB", "c": "keyC"} {
+		p, _ := f.Profile(name)
+		k, err := p.ResolveAPIKey()
+		assert.NoError(t, err)
+		assert.Equal(t, key, k)
+	}
+
+	_, err = Profile{Name: "empty"}.ResolveAPIKey()
+	assert.Error(t, err)
+
+	_, err = Profile{APIKeyCommand: "exit 1"}.ResolveAPIKey()
+	assert.Error(t, err)
+}
+
+func TestLoad(t *testing.T) {
+	clearEnv(t)
+	t.Setenv(ConfigEnvKey, writeConfig(t))
+
+	p, err := Load("b")
+	assert.NoError(t, err)
+
+	a, err := p.API()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.b.com", a.BaseURL)
+	assert.Equal(t, "keyB", a.APIKey)
+
+	p, err = Load("a")
+	assert.NoError(t, err)
+	w, err := p.Warren()
+	assert.NoError(t, err)
+	assert.Equal(t, "jkt01", w.VPC.(*vpc.Client).Location)
+	assert.Equal(t, 123, w.ObjectStorage.(*objectstorage.Client).BillingAccountID)
+	assert.Equal(t, 123, w.IP.(*ip.Client).BillingAccountID)
+	assert.Equal(t, 123, w.VM.(*vm.Client).BillingAccountID)
+}
+
+func TestLoad_NoFile(t *testing.T) {
+	clearEnv(t)
+	t.Setenv(ConfigEnvKey, filepath.Join(t.TempDir(), "missing.yaml"))
+	t.Setenv(api.BaseURLEnvKey, "https://api.env.com")
+	t.Setenv(api.APIKeyEnvKey, "keyEnv")
+
+	// env only
+	p, err := Load("")
+	assert.NoError(t, err)
+	a, err := p.API()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.env.com", a.BaseURL)
+
+	// explicit profile must exist
+	_, err = Load("a")
+	assert.Error(t, err)
+}
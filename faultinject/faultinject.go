@@ -0,0 +1,194 @@
+// Package faultinject provides http.RoundTripper that injects faults (latency, error status,
+// dropped connection, truncated or invalid json, transport errors) into matching requests,
+// so failure paths can be tested deterministically.
+//
+//	t := faultinject.New(nil,
+//		faultinject.Rule{Method: "GET", Path: "/v1/*/network/networks", Status: 503, Nth: 1},
+//		faultinject.Rule{Path: "/v1/storage/*", Latency: 2 * time.Second},
+//	)
+//	a.HTTPClient = &http.Client{Transport: t}
+package faultinject
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInjected is the default transport error returned by rule with Fail set
+var ErrInjected = errors.New("faultinject: injected transport error")
+
+// Rule describes a fault and requests it applies to.
+// When multiple faults are set, latency is applied first, then the request either fails
+// (Err, Fail or Status) or the real response body is altered (DropConnection, Truncate, InvalidJSON).
+type Rule struct {
+	// Method to match, any method if empty.
+	Method string
+	// Path pattern to match using path.Match syntax (e.g. `/v1/*/network/*`), any path if empty.
+	Path string
+	// Nth only applies the rule to the Nth (1-based) matching call, every matching call if zero.
+	Nth int
+
+	// Latency delays the request, respecting request context.
+	Latency time.Duration
+	// Err is returned as transport error.
+	Err error
+	// Fail returns ErrInjected as transport error when Err is not set.
+	Fail bool
+	// Status returns response with this status code (and Body) without calling the server.
+	Status int
+	// Body is the response body used with Status.
+	Body string
+	// DropConnection makes reading the response body fail with io.ErrUnexpectedEOF halfway through.
+	DropConnection bool
+	// Truncate cuts the response body in half.
+	Truncate bool
+	// InvalidJSON replaces the response body with invalid json.
+	InvalidJSON bool
+}
+
+func (r *Rule) matches(req *http.Request) bool {
+	if r.Method != "" && !strings.EqualFold(r.Method, req.Method) {
+		return false
+	}
+	if r.Path != "" {
+		ok, err := path.Match(r.Path, req.URL.Path)
+		if err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Transport is http.RoundTripper that injects faults defined by rules.
+// The first applicable rule wins, requests that don't match any rule are sent as is.
+type Transport struct {
+	next  http.RoundTripper
+	rules []Rule
+
+	mu     sync.Mutex
+	counts []int
+}
+
+// New creates Transport wrapping next (http.DefaultTransport if nil)
+func New(next http.RoundTripper, rules ...Rule) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Transport{
+		next:   next,
+		rules:  rules,
+		counts: make([]int, len(rules)),
+	}
+}
+
+// Calls returns number of calls matched by rule with given index so far
+func (t *Transport) Calls(rule int) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.counts[rule]
+}
+
+// rule returns the rule applicable to req, nil if none
+func (t *Transport) rule(req *http.Request) *Rule {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var applied *Rule
+	for i := range t.rules {
+		r := &t.rules[i]
+		if !r.matches(req) {
+			continue
+		}
+		t.counts[i]++
+		if applied == nil && (r.Nth == 0 || r.Nth == t.counts[i]) {
+			applied = r
+		}
+	}
+	return applied
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := t.rule(req)
+	if r == nil {
+		return t.next.RoundTrip(req)
+	}
+
+	if r.Latency > 0 {
+		timer := time.NewTimer(r.Latency)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			closeBody(req)
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+
+	switch {
+	case r.Err != nil:
+		closeBody(req)
+		return nil, r.Err
+	case r.Fail:
+		closeBody(req)
+		return nil, ErrInjected
+	case r.Status != 0:
+		closeBody(req)
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+			StatusCode:    r.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{},
+			Body:          io.NopCloser(strings.NewReader(r.Body)),
+			ContentLength: int64(len(r.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	res, err := t.next.RoundTrip(req)
+	if err != nil || !(r.DropConnection || r.Truncate || r.InvalidJSON) {
+		return res, err
+	}
+	b, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.ContentLength = -1
+	res.Header.Del("Content-Length")
+
+	switch {
+	case r.DropConnection:
+		res.Body = io.NopCloser(io.MultiReader(bytes.NewReader(b[:len(b)/2]), errReader{io.ErrUnexpectedEOF}))
+	case r.Truncate:
+		res.Body = io.NopCloser(bytes.NewReader(b[:len(b)/2]))
+	case r.InvalidJSON:
+		res.Body = io.NopCloser(strings.NewReader(`{"invalid json`))
+	}
+	return res, nil
+}
+
+// closeBody closes body of the request which is not sent, as required from http.RoundTripper
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// errReader always fails with err
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
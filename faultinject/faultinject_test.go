@@ -0,0 +1,156 @@
+package faultinject
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ekaputra07/warren-go/api"
+	"github.com/stretchr/testify/assert"
+)
+
+const body = `[{"name": "a"}, {"name": "b"}]`
+
+type item struct {
+	Name string `json:"name"`
+}
+
+func newAPI(t *testing.T, rules ...Rule) (*api.API, *Transport) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(s.Close)
+
+	tr := New(s.Client().Transport, rules...)
+	a := api.New(s.URL, "secret")
+	a.HTTPClient = &http.Client{Transport: tr}
+	return a, tr
+}
+
+func list(ctx context.Context, a *api.API, path string) ([]item, error) {
+	return api.Do[[]item](ctx, a, api.RequestConfig{Method: "GET", Path: path})
+}
+
+func TestNoMatch(t *testing.T) {
+	a, tr := newAPI(t, Rule{Method: "POST", Status: 500}, Rule{Path: "/other", Status: 500})
+
+	items, err := list(context.Background(), a, "/test")
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+	assert.Equal(t, 0, tr.Calls(0))
+	assert.Equal(t, 0, tr.Calls(1))
+}
+
+func TestStatus(t *testing.T) {
+	a, _ := newAPI(t, Rule{Method: "GET", Path: "/v1/*/network/networks", Status: 503, Body: `{"message": "down"}`})
+
+	_, err := list(context.Background(), a, "/v1/jkt01/network/networks")
+	var e *api.Error
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, 503, e.StatusCode)
+	assert.Equal(t, "down", e.Message)
+}
+
+func TestNth(t *testing.T) {
+	a, tr := newAPI(t, Rule{Path: "/test", Status: 500, Nth: 2})
+
+	_, err := list(context.Background(), a, "/test")
+	assert.NoError(t, err)
+	_, err = list(context.Background(), a, "/test")
+	assert.True(t, api.HasStatus(err, 500))
+	_, err = list(context.Background(), a, "/test")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, tr.Calls(0))
+}
+
+func TestNth_Retry(t *testing.T) {
+	a, _ := newAPI(t, Rule{Path: "/test", Status: 503, Nth: 1})
+	a.RetryPolicy = &api.Backoff{MaxAttempts: 2, BaseBackoff: time.Millisecond}
+
+	items, err := list(context.Background(), a, "/test")
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+}
+
+func TestLatency(t *testing.T) {
+	a, _ := newAPI(t, Rule{Latency: 50 * time.Millisecond})
+
+	start := time.Now()
+	_, err := list(context.Background(), a, "/test")
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = list(ctx, a, "/test")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestTransportError(t *testing.T) {
+	custom := errors.New("connection reset by peer")
+	a, _ := newAPI(t, Rule{Path: "/fail", Fail: true}, Rule{Path: "/custom", Err: custom})
+
+	_, err := list(context.Background(), a, "/fail")
+	assert.ErrorIs(t, err, ErrInjected)
+	_, err = list(context.Background(), a, "/custom")
+	assert.ErrorIs(t, err, custom)
+}
+
+func TestDropConnection(t *testing.T) {
+	a, _ := newAPI(t, Rule{DropConnection: true})
+
+	_, err := list(context.Background(), a, "/test")
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	// streaming decoder sees the dropped connection too
+	_, err = api.Do[[]item](context.Background(), a, api.RequestConfig{Method: "GET", Path: "/test", Stream: true})
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestTruncate(t *testing.T) {
+	a, _ := newAPI(t, Rule{Truncate: true})
+
+	_, err := list(context.Background(), a, "/test")
+	assert.Error(t, err)
+}
+
+func TestInvalidJSON(t *testing.T) {
+	a, _ := newAPI(t, Rule{InvalidJSON: true})
+
+	_, err := list(context.Background(), a, "/test")
+	assert.Error(t, err)
+	assert.False(t, api.HasStatus(err, 200))
+}
+
+// trackedBody reports whether it was closed
+type trackedBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *trackedBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestRequestBodyClosed(t *testing.T) {
+	rules := map[string]Rule{
+		"error":  {Err: errors.New("boom")},
+		"fail":   {Fail: true},
+		"status": {Status: 500},
+	}
+	for name, rule := range rules {
+		body := &trackedBody{Reader: strings.NewReader("name=a")}
+		req, _ := http.NewRequest("POST", "http://example.com/test", body)
+		res, _ := New(nil, rule).RoundTrip(req)
+		if res != nil {
+			res.Body.Close()
+		}
+		assert.True(t, body.closed, name)
+	}
+}
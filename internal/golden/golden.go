@@ -0,0 +1,37 @@
+// Package golden provides helpers for contract tests that decode golden fixtures from testdata.
+package golden
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ekaputra07/warren-go/api"
+	"github.com/stretchr/testify/assert"
+)
+
+// Server serves testdata/<name> as response of every request, the API decodes strictly
+// so fields missing from the response types fail the test.
+func Server(t *testing.T, name string) (*api.API, []byte) {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(b)
+	})
+	t.Cleanup(s.Close)
+	a.StrictDecoding = true
+	return a, b
+}
+
+// AssertRoundTrip asserts v encodes back to the golden fixture
+func AssertRoundTrip(t *testing.T, golden []byte, v any) {
+	t.Helper()
+	b, err := json.Marshal(v)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(golden), string(b))
+}
@@ -0,0 +1,26 @@
+// Package scopetest provides helpers for tests that clients are safe to share between goroutines using different scopes.
+package scopetest
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ekaputra07/warren-go/api"
+)
+
+// Concurrent calls each fn with 20 different scopes, every call in its own goroutine, and waits for all of them.
+// Run with -race so a client modified by scoping a call fails the test.
+func Concurrent(fns ...func(s api.Scope)) {
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		s := api.Scope{Location: fmt.Sprintf("loc%02d", i), BillingAccountID: 100 + i}
+		for _, fn := range fns {
+			wg.Add(1)
+			go func(fn func(api.Scope)) {
+				defer wg.Done()
+				fn(s)
+			}(fn)
+		}
+	}
+	wg.Wait()
+}
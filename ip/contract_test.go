@@ -0,0 +1,62 @@
+package ip
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ekaputra07/warren-go/internal/golden"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var goldenIP = IPAddressInfo{
+	ID:                     3141,
+	Address:                "103.41.204.17",
+	UserID:                 1234,
+	BillingAccountID:       5678,
+	Type:                   "public",
+	NetworkID:              uuid.NullUUID{UUID: uuid.MustParse("5e2f8c1a-7d4b-4a9e-b3c6-1f0d9e8a7b52"), Valid: true},
+	Name:                   "web",
+	Enabled:                true,
+	CreatedAt:              "2023-02-01 07:00:00",
+	UpdatedAt:              "2023-02-03 11:22:33",
+	AssignedTo:             uuid.NullUUID{UUID: uuid.MustParse("a1b2c3d4-e5f6-4a7b-8c9d-0e1f2a3b4c5d"), Valid: true},
+	AssignedToResourceType: "virtual_machine",
+	AssignedToPrivateIP:    "10.42.0.5",
+}
+
+func TestContract_GetFloatingIP(t *testing.T) {
+	a, fixture := golden.Server(t, "ip_address.json")
+
+	info, err := NewClient(a, loc).GetFloatingIP(context.Background(), goldenIP.Address)
+	assert.NoError(t, err)
+	assert.Equal(t, goldenIP, info)
+	golden.AssertRoundTrip(t, fixture, info)
+}
+
+func TestContract_CreateFloatingIP(t *testing.T) {
+	a, _ := golden.Server(t, "ip_address.json")
+
+	info := IPAddressInfo{Name: "web", BillingAccountID: 5678}
+	assert.NoError(t, NewClient(a, loc).CreateFloatingIP(context.Background(), &info))
+	assert.Equal(t, goldenIP, info)
+}
+
+func TestContract_ListFloatingIPs(t *testing.T) {
+	a, fixture := golden.Server(t, "ip_addresses.json")
+
+	ips, err := NewClient(a, loc).ListFloatingIPs(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []IPAddressInfo{goldenIP, {
+		ID:               3142,
+		Address:          "103.41.204.18",
+		UserID:           1234,
+		BillingAccountID: 5678,
+		Type:             "public",
+		Name:             "spare",
+		Enabled:          true,
+		CreatedAt:        "2023-02-01 07:00:00",
+		UpdatedAt:        "2023-02-03 11:22:33",
+	}}, ips)
+	golden.AssertRoundTrip(t, fixture, ips)
+}
@@ -2,7 +2,7 @@ package ip
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/ekaputra07/warren-go/api"
@@ -16,103 +16,212 @@ func NewClient(client *api.API, location string) *Client {
 	}
 }
 
+// WithLocation returns copy of the client scoped to given data center location
+func (c *Client) WithLocation(location string) *Client {
+	cc := *c
+	cc.Location = location
+	return &cc
+}
+
+// WithBillingAccount returns copy of the client scoped to given billing account
+func (c *Client) WithBillingAccount(id int) *Client {
+	cc := *c
+	cc.BillingAccountID = id
+	return &cc
+}
+
+// scope returns scope of the call, which is the client scope unless overridden by options.
+// Options overriding fields other than supported are rejected.
+func (c *Client) scope(opts []api.CallOption, supported api.ScopeField) (api.Scope, error) {
+	return api.Scope{Location: c.Location, BillingAccountID: c.BillingAccountID}.Resolve(opts, supported)
+}
+
+// location returns data center location of the call for methods that don't use billing account
+func (c *Client) location(opts []api.CallOption) (string, error) {
+	s, err := c.scope(opts, api.ScopeLocation)
+	return s.Location, err
+}
+
 // ListFloatingIPs https://api.warren.io/#list-floating-ips
-func (c *Client) ListFloatingIPs(ctx context.Context) ([]IPAddressInfo, error) {
-	rc := api.RequestConfig{
-		Method: "GET",
-		Path:   fmt.Sprintf("/v1/%s/network/ip_addresses", c.Location),
-	}
-	res := c.API.JSONRequest(ctx, rc)
-	if res.Error != nil {
-		return nil, res.Error
-	}
-	var ips []IPAddressInfo
-	if err := json.Unmarshal(res.Body, &ips); err != nil {
+func (c *Client) ListFloatingIPs(ctx context.Context, opts ...api.CallOption) ([]IPAddressInfo, error) {
+	loc, err := c.location(opts)
+	if err != nil {
 		return nil, err
 	}
-	return ips, nil
+	rc := api.RequestConfig{
+		Method:    "GET",
+		Path:      fmt.Sprintf("/v1/%s/network/ip_addresses", loc),
+		Operation: "ip.ListFloatingIPs",
+		Location:  loc,
+	}
+	return api.Do[[]IPAddressInfo](ctx, c.API, rc)
 }
 
 // CreateFloatingIP https://api.warren.io/#create-floating-ip
-func (c *Client) CreateFloatingIP(ctx context.Context, info *IPAddressInfo) error {
-	if info.BillingAccountID == 0 {
-		return fmt.Errorf("BillingAccountID with value of %v is invalid", info.BillingAccountID)
+// The IP is billed to the client billing account unless info.BillingAccountID is set.
+func (c *Client) CreateFloatingIP(ctx context.Context, info *IPAddressInfo, opts ...api.CallOption) error {
+	return c.createFloatingIP(ctx, info, "", opts)
+}
+
+func (c *Client) createFloatingIP(ctx context.Context, info *IPAddressInfo, idempotencyKey string, opts []api.CallOption) error {
+	s, err := c.scope(opts, api.ScopeLocation|api.ScopeBillingAccount)
+	if err != nil {
+		return err
+	}
+	billingAccountID := info.BillingAccountID
+	if billingAccountID == 0 {
+		billingAccountID = s.BillingAccountID
+	}
+	if billingAccountID == 0 {
+		return fmt.Errorf("BillingAccountID with value of %v is invalid", billingAccountID)
 	}
 
 	rc := api.RequestConfig{
 		Method: "POST",
-		Path:   fmt.Sprintf("/v1/%s/network/ip_addresses", c.Location),
+		Path:   fmt.Sprintf("/v1/%s/network/ip_addresses", s.Location),
 		JSON: map[string]any{
 			"name":               info.Name,
-			"billing_account_id": info.BillingAccountID,
+			"billing_account_id": billingAccountID,
 		},
+		IdempotencyKey: idempotencyKey,
+		Operation:      "ip.CreateFloatingIP",
+		Location:       s.Location,
 	}
-	res := c.API.JSONRequest(ctx, rc)
-	if res.Error != nil {
-		return res.Error
+	return api.DoInto(ctx, c.API, rc, info)
+}
+
+// EnsureFloatingIP creates floating IP unless one with the same name already exists, see api.NewIdempotencyKey
+func (c *Client) EnsureFloatingIP(ctx context.Context, info IPAddressInfo, opts ...api.CallOption) (IPAddressInfo, bool, error) {
+	if info.Name == "" {
+		return IPAddressInfo{}, false, errors.New("name is required to ensure floating IP")
 	}
-	return json.Unmarshal(res.Body, info)
+	s, err := c.scope(opts, api.ScopeLocation|api.ScopeBillingAccount)
+	if err != nil {
+		return IPAddressInfo{}, false, err
+	}
+	scoped := c.WithLocation(s.Location).WithBillingAccount(s.BillingAccountID)
+	if found, ok, err := scoped.findFloatingIP(ctx, info.Name); err != nil || ok {
+		return found, false, err
+	}
+
+	err = scoped.createFloatingIP(ctx, &info, api.NewIdempotencyKey(), nil)
+	if err == nil {
+		return info, true, nil
+	}
+	if !api.MayHaveSucceeded(err) && !api.IsConflict(err) {
+		return IPAddressInfo{}, false, err
+	}
+	found, ok, lerr := scoped.findFloatingIP(ctx, info.Name)
+	if lerr != nil || !ok {
+		return IPAddressInfo{}, false, err
+	}
+	return found, !api.IsConflict(err), nil
 }
 
-// GetFloatingIP https://api.warren.io/#get-floating-ip
-func (c *Client) GetFloatingIP(ctx context.Context, address string) (IPAddressInfo, error) {
-	var ip IPAddressInfo
-	rc := api.RequestConfig{
-		Method: "GET",
-		Path:   fmt.Sprintf("/v1/%s/network/ip_addresses/%s", c.Location, address),
+// findFloatingIP returns floating IP with given name
+func (c *Client) findFloatingIP(ctx context.Context, name string) (IPAddressInfo, bool, error) {
+	ips, err := c.ListFloatingIPs(ctx)
+	if err != nil {
+		return IPAddressInfo{}, false, err
 	}
-	res := c.API.JSONRequest(ctx, rc)
-	if res.Error != nil {
-		return ip, res.Error
+	for _, ip := range ips {
+		if ip.Name == name {
+			return ip, true, nil
+		}
 	}
-	if err := json.Unmarshal(res.Body, &ip); err != nil {
-		return ip, err
+	return IPAddressInfo{}, false, nil
+}
+
+// GetFloatingIP https://api.warren.io/#get-floating-ip
+func (c *Client) GetFloatingIP(ctx context.Context, address string, opts ...api.CallOption) (IPAddressInfo, error) {
+	loc, err := c.location(opts)
+	if err != nil {
+		return IPAddressInfo{}, err
 	}
-	return ip, nil
+	rc := api.RequestConfig{
+		Method:     "GET",
+		Path:       fmt.Sprintf("/v1/%s/network/ip_addresses/%s", loc, address),
+		Operation:  "ip.GetFloatingIP",
+		Location:   loc,
+		ResourceID: address,
+	}
+	return api.Do[IPAddressInfo](ctx, c.API, rc)
 }
 
 // UpdateFloatingIP https://api.warren.io/#update-floating-ip
-func (c *Client) UpdateFloatingIP(ctx context.Context, info IPAddressInfo) error {
+// The IP is billed to the client billing account unless info.BillingAccountID is set.
+func (c *Client) UpdateFloatingIP(ctx context.Context, info IPAddressInfo, opts ...api.CallOption) error {
+	s, err := c.scope(opts, api.ScopeLocation|api.ScopeBillingAccount)
+	if err != nil {
+		return err
+	}
+	if info.BillingAccountID == 0 {
+		info.BillingAccountID = s.BillingAccountID
+	}
 	if info.BillingAccountID == 0 {
 		return fmt.Errorf("BillingAccountID with value of %v is invalid", info.BillingAccountID)
 	}
 
 	rc := api.RequestConfig{
 		Method: "PATCH",
-		Path:   fmt.Sprintf("/v1/%s/network/ip_addresses/%s", c.Location, info.Address),
+		Path:   fmt.Sprintf("/v1/%s/network/ip_addresses/%s", s.Location, info.Address),
 		JSON: map[string]any{
 			"name":               info.Name,
 			"billing_account_id": info.BillingAccountID,
 		},
+		Operation:  "ip.UpdateFloatingIP",
+		Location:   s.Location,
+		ResourceID: info.Address,
 	}
-	return c.API.JSONRequest(ctx, rc).Error
+	return api.DoNoContent(ctx, c.API, rc)
 }
 
 // DeleteFloatingIP https://api.warren.io/#delete-floating-ip
-func (c *Client) DeleteFloatingIP(ctx context.Context, address string) error {
+func (c *Client) DeleteFloatingIP(ctx context.Context, address string, opts ...api.CallOption) error {
+	loc, err := c.location(opts)
+	if err != nil {
+		return err
+	}
 	rc := api.RequestConfig{
-		Method: "DELETE",
-		Path:   fmt.Sprintf("/v1/%s/network/ip_addresses/%s", c.Location, address),
+		Method:     "DELETE",
+		Path:       fmt.Sprintf("/v1/%s/network/ip_addresses/%s", loc, address),
+		Operation:  "ip.DeleteFloatingIP",
+		Location:   loc,
+		ResourceID: address,
 	}
-	return c.API.JSONRequest(ctx, rc).Error
+	return api.DoNoContent(ctx, c.API, rc)
 }
 
 // AssignFloatingIPToVM https://api.warren.io/#assign-floating-ip
-func (c *Client) AssignFloatingIPToVM(ctx context.Context, address string, vmUUID uuid.UUID) error {
+func (c *Client) AssignFloatingIPToVM(ctx context.Context, address string, vmUUID uuid.UUID, opts ...api.CallOption) error {
+	loc, err := c.location(opts)
+	if err != nil {
+		return err
+	}
 	rc := api.RequestConfig{
-		Method: "POST",
-		Path:   fmt.Sprintf("/v1/%s/network/ip_addresses/%s/assign", c.Location, address),
-		JSON:   map[string]any{"vm_uuid": vmUUID},
+		Method:     "POST",
+		Path:       fmt.Sprintf("/v1/%s/network/ip_addresses/%s/assign", loc, address),
+		JSON:       map[string]any{"vm_uuid": vmUUID},
+		Operation:  "ip.AssignFloatingIPToVM",
+		Location:   loc,
+		ResourceID: address,
 	}
-	return c.API.JSONRequest(ctx, rc).Error
+	return api.DoNoContent(ctx, c.API, rc)
 }
 
 // UnassignFloatingIPFromVM https://api.warren.io/#un-assign-floating-ip
-func (c *Client) UnassignFloatingIPFromVM(ctx context.Context, address string, vmUUID uuid.UUID) error {
+func (c *Client) UnassignFloatingIPFromVM(ctx context.Context, address string, vmUUID uuid.UUID, opts ...api.CallOption) error {
+	loc, err := c.location(opts)
+	if err != nil {
+		return err
+	}
 	rc := api.RequestConfig{
-		Method: "POST",
-		Path:   fmt.Sprintf("/v1/%s/network/ip_addresses/%s/unassign", c.Location, address),
-		JSON:   map[string]any{"vm_uuid": vmUUID},
+		Method:     "POST",
+		Path:       fmt.Sprintf("/v1/%s/network/ip_addresses/%s/unassign", loc, address),
+		JSON:       map[string]any{"vm_uuid": vmUUID},
+		Operation:  "ip.UnassignFloatingIPFromVM",
+		Location:   loc,
+		ResourceID: address,
 	}
-	return c.API.JSONRequest(ctx, rc).Error
+	return api.DoNoContent(ctx, c.API, rc)
 }
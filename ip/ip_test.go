@@ -6,10 +6,15 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/ekaputra07/warren-go/api"
+	"github.com/ekaputra07/warren-go/internal/scopetest"
+	"github.com/ekaputra07/warren-go/wait"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 var (
@@ -128,3 +133,140 @@ func TestUnassignFloatingIPFromVM(t *testing.T) {
 	ip := Client{API: a, Location: loc}
 	ip.UnassignFloatingIPFromVM(context.Background(), address, vmUUID)
 }
+
+func TestDeleteFloatingIP_Conflict(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	})
+	defer s.Close()
+
+	ip := Client{API: a, Location: loc}
+	err := ip.DeleteFloatingIP(context.Background(), address)
+	assert.True(t, api.IsConflict(err))
+}
+
+func TestAssignFloatingIPToVM_Tracing(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {})
+	defer s.Close()
+
+	exp := tracetest.NewInMemoryExporter()
+	a.Tracing = &api.Tracing{TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))}
+
+	ip := Client{API: a, Location: loc}
+	ip.AssignFloatingIPToVM(context.Background(), address, vmUUID)
+
+	spans := exp.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "ip.AssignFloatingIPToVM", spans[0].Name)
+	assert.Contains(t, spans[0].Attributes, api.AttrLocation.String(loc))
+	assert.Contains(t, spans[0].Attributes, api.AttrResourceID.String(address))
+}
+
+func TestDeleteFloatingIP_InLocation(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, fmt.Sprintf("/v1/sgp01/network/ip_addresses/%s", address), r.RequestURI)
+	})
+	defer s.Close()
+
+	ip := NewClient(a, loc)
+	assert.NoError(t, ip.DeleteFloatingIP(context.Background(), address, api.InLocation("sgp01")))
+	assert.Equal(t, "sgp01", ip.WithLocation("sgp01").Location)
+	assert.Equal(t, loc, ip.Location)
+}
+
+func TestCreateFloatingIP_BillingAccount(t *testing.T) {
+	var billed []float64
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		var data map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&data)
+		billed = append(billed, data["billing_account_id"].(float64))
+	})
+	defer s.Close()
+
+	ip := NewClient(a, loc).WithBillingAccount(123)
+	assert.NoError(t, ip.CreateFloatingIP(context.Background(), &IPAddressInfo{Name: "Test"}))
+	assert.NoError(t, ip.CreateFloatingIP(context.Background(), &IPAddressInfo{Name: "Test"}, api.ForBillingAccount(456)))
+	assert.NoError(t, ip.CreateFloatingIP(context.Background(), &IPAddressInfo{Name: "Test", BillingAccountID: 789}))
+	assert.Equal(t, []float64{123, 456, 789}, billed)
+
+	// billing account doesn't apply to deleting
+	err := ip.DeleteFloatingIP(context.Background(), address, api.ForBillingAccount(456))
+	assert.ErrorIs(t, err, api.ErrUnsupportedOption)
+	assert.Len(t, billed, 3)
+}
+
+// run with -race to verify a shared client is safe to use with different scopes
+func TestClient_Concurrent(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		var data map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&data)
+		data["name"] = r.URL.Path
+		json.NewEncoder(w).Encode(data)
+	})
+	defer s.Close()
+	c := NewClient(a, loc).WithBillingAccount(1)
+
+	check := func(s api.Scope, info IPAddressInfo, err error) {
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("/v1/%s/network/ip_addresses", s.Location), info.Name)
+		assert.Equal(t, s.BillingAccountID, info.BillingAccountID)
+	}
+	scopetest.Concurrent(
+		func(s api.Scope) {
+			var info IPAddressInfo
+			err := c.WithLocation(s.Location).WithBillingAccount(s.BillingAccountID).CreateFloatingIP(context.Background(), &info)
+			check(s, info, err)
+		},
+		func(s api.Scope) {
+			var info IPAddressInfo
+			err := c.CreateFloatingIP(context.Background(), &info, api.InLocation(s.Location), api.ForBillingAccount(s.BillingAccountID))
+			check(s, info, err)
+		},
+	)
+	assert.Equal(t, loc, c.Location)
+	assert.Equal(t, 1, c.BillingAccountID)
+}
+
+func TestEnsureFloatingIP_Exists(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		w.Write([]byte(`[{"address": "1.2.3.4", "name": "web"}]`))
+	})
+	defer s.Close()
+
+	ip := NewClient(a, loc)
+	info, created, err := ip.EnsureFloatingIP(context.Background(), IPAddressInfo{Name: "web", BillingAccountID: 1})
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, "1.2.3.4", info.Address)
+
+	_, _, err = ip.EnsureFloatingIP(context.Background(), IPAddressInfo{BillingAccountID: 1})
+	assert.Error(t, err)
+}
+
+func TestWaitForAssignment(t *testing.T) {
+	var polls int
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, fmt.Sprintf("/v1/%s/network/ip_addresses/%s", loc, address), r.RequestURI)
+		polls++
+		info := IPAddressInfo{Address: address}
+		if polls > 1 {
+			info.AssignedTo = uuid.NullUUID{UUID: vmUUID, Valid: true}
+		}
+		json.NewEncoder(w).Encode(info)
+	})
+	defer s.Close()
+
+	c := NewClient(a, loc)
+	info, err := WaitForAssignment(context.Background(), c, address, vmUUID, wait.Config{Interval: time.Millisecond})
+	assert.NoError(t, err)
+	assert.Equal(t, vmUUID, info.AssignedTo.UUID)
+
+	// never unassigned
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = WaitForAssignment(ctx, c, address, uuid.Nil, wait.Config{Interval: time.Millisecond})
+	assert.ErrorIs(t, err, wait.ErrTimeout)
+	assert.ErrorContains(t, err, fmt.Sprintf("floating IP %s to be unassigned", address))
+	assert.ErrorContains(t, err, fmt.Sprintf("last seen: assigned to %s", vmUUID))
+}
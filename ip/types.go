@@ -1,13 +1,35 @@
 package ip
 
 import (
+	"context"
+
 	"github.com/ekaputra07/warren-go/api"
 	"github.com/google/uuid"
 )
 
+// Service is implemented by Client, use it in place of *Client to be able to mock the API.
+// Every method accepts call options to override scope of the client for that single call,
+// options that don't apply to the method are rejected with api.ErrUnsupportedOption.
+type Service interface {
+	ListFloatingIPs(ctx context.Context, opts ...api.CallOption) ([]IPAddressInfo, error)
+	CreateFloatingIP(ctx context.Context, info *IPAddressInfo, opts ...api.CallOption) error
+	EnsureFloatingIP(ctx context.Context, info IPAddressInfo, opts ...api.CallOption) (IPAddressInfo, bool, error)
+	GetFloatingIP(ctx context.Context, address string, opts ...api.CallOption) (IPAddressInfo, error)
+	UpdateFloatingIP(ctx context.Context, info IPAddressInfo, opts ...api.CallOption) error
+	DeleteFloatingIP(ctx context.Context, address string, opts ...api.CallOption) error
+	AssignFloatingIPToVM(ctx context.Context, address string, vmUUID uuid.UUID, opts ...api.CallOption) error
+	UnassignFloatingIPFromVM(ctx context.Context, address string, vmUUID uuid.UUID, opts ...api.CallOption) error
+}
+
+var _ Service = (*Client)(nil)
+
+// Client is safe for concurrent use, use WithLocation/WithBillingAccount or api.InLocation/api.ForBillingAccount
+// call options instead of modifying Location or BillingAccountID of a shared client.
+// Billing account is used by the methods creating or updating floating IP only.
 type Client struct {
-	API      *api.API
-	Location string
+	API              *api.API
+	Location         string
+	BillingAccountID int
 }
 
 type IPAddressInfo struct {
@@ -0,0 +1,34 @@
+package ip
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ekaputra07/warren-go/api"
+	"github.com/ekaputra07/warren-go/wait"
+	"github.com/google/uuid"
+)
+
+// WaitForAssignment polls the floating IP until it's assigned to given VM, or unassigned if vmUUID is uuid.Nil.
+// See wait.Until.
+func WaitForAssignment(ctx context.Context, s Service, address string, vmUUID uuid.UUID, cfg wait.Config, opts ...api.CallOption) (IPAddressInfo, error) {
+	desc := fmt.Sprintf("floating IP %s to be assigned to %s", address, vmUUID)
+	if vmUUID == uuid.Nil {
+		desc = fmt.Sprintf("floating IP %s to be unassigned", address)
+	}
+	return wait.Until(ctx, cfg, wait.Condition[IPAddressInfo]{
+		Description: desc,
+		Poll: func(ctx context.Context) (IPAddressInfo, error) {
+			return s.GetFloatingIP(ctx, address, opts...)
+		},
+		Done: func(info IPAddressInfo) bool {
+			return info.AssignedTo.Valid == (vmUUID != uuid.Nil) && info.AssignedTo.UUID == vmUUID
+		},
+		State: func(info IPAddressInfo) string {
+			if !info.AssignedTo.Valid {
+				return "unassigned"
+			}
+			return fmt.Sprintf("assigned to %s", info.AssignedTo.UUID)
+		},
+	})
+}
@@ -0,0 +1,21 @@
+package location
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ekaputra07/warren-go/internal/golden"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContract_ListLocations(t *testing.T) {
+	a, fixture := golden.Server(t, "locations.json")
+
+	locs, err := NewClient(a).ListLocations(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []Location{
+		{DisplayName: "Jakarta", IsDefault: true, IsPreferred: true, Description: "Data center in Jakarta", OrderNr: 1, Slug: "jkt01", CountryCode: "ID"},
+		{DisplayName: "Singapore", Description: "Data center in Singapore", OrderNr: 2, Slug: "sgp01", CountryCode: "SG"},
+	}, locs)
+	golden.AssertRoundTrip(t, fixture, locs)
+}
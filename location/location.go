@@ -2,7 +2,6 @@ package location
 
 import (
 	"context"
-	"encoding/json"
 
 	"github.com/ekaputra07/warren-go/api"
 )
@@ -24,24 +23,28 @@ func NewClient(client *api.API) *Client {
 	}
 }
 
+// Service is implemented by Client, use it in place of *Client to be able to mock the API.
+// Every method accepts call options like the other services, but locations are neither location-scoped
+// nor billed, so any scope override is rejected with api.ErrUnsupportedOption.
+type Service interface {
+	ListLocations(ctx context.Context, opts ...api.CallOption) ([]Location, error)
+}
+
+var _ Service = (*Client)(nil)
+
 type Client struct {
 	API *api.API
 }
 
 // ListLocations https://api.warren.io/#list-locations
-func (c *Client) ListLocations(ctx context.Context) ([]Location, error) {
-	rc := api.RequestConfig{
-		Method: "GET",
-		Path:   "/v1/config/locations",
-	}
-	resp := c.API.FormRequest(ctx, rc)
-	if resp.Error != nil {
-		return nil, resp.Error
-	}
-
-	var locations []Location
-	if err := json.Unmarshal(resp.Body, &locations); err != nil {
+func (c *Client) ListLocations(ctx context.Context, opts ...api.CallOption) ([]Location, error) {
+	if err := api.CheckOptions(opts, 0); err != nil {
 		return nil, err
 	}
-	return locations, nil
+	rc := api.RequestConfig{
+		Method:    "GET",
+		Path:      "/v1/config/locations",
+		Operation: "location.ListLocations",
+	}
+	return api.Do[[]Location](ctx, c.API, rc)
 }
@@ -19,3 +19,34 @@ func TestListLocations(t *testing.T) {
 	lc := Client{API: a}
 	lc.ListLocations(context.Background())
 }
+
+func TestListLocations_RateLimited(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	defer s.Close()
+
+	lc := Client{API: a}
+	_, err := lc.ListLocations(context.Background())
+	assert.True(t, api.IsRateLimited(err))
+}
+
+func TestListLocations_Decode(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"display_name": "Jakarta", "is_default": true, "slug": "jkt01", "country_code": "ID"}]`))
+	})
+	defer s.Close()
+
+	lc := Client{API: a}
+	locations, err := lc.ListLocations(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []Location{{DisplayName: "Jakarta", IsDefault: true, Slug: "jkt01", CountryCode: "ID"}}, locations)
+}
+
+func TestListLocations_UnsupportedOption(t *testing.T) {
+	lc := NewClient(api.Default)
+	_, err := lc.ListLocations(context.Background(), api.InLocation("jkt01"))
+	assert.ErrorIs(t, err, api.ErrUnsupportedOption)
+	_, err = lc.ListLocations(context.Background(), api.ForBillingAccount(1))
+	assert.ErrorIs(t, err, api.ErrUnsupportedOption)
+}
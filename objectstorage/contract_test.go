@@ -0,0 +1,97 @@
+package objectstorage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ekaputra07/warren-go/internal/golden"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	goldenKeys = []S3Credential{
+		{AccessKey: "AKIAEXAMPLE1", SecretKey: "c2VjcmV0MQ", UserID: "john"},
+		{AccessKey: "AKIAEXAMPLE2", SecretKey: "c2VjcmV0Mg", UserID: "john"},
+	}
+	goldenBucket = S3Bucket{
+		Name:             "assets",
+		SizeBytes:        1048576,
+		BillingAccountID: 5678,
+		NumObjects:       12,
+		CreatedAt:        "2023-04-04 04:04:04",
+		ModifiedAt:       "2023-04-05 05:05:05",
+	}
+)
+
+func TestContract_GetS3ApiURL(t *testing.T) {
+	a, fixture := golden.Server(t, "s3_api_url.json")
+
+	u, err := NewClient(a).GetS3ApiURL(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"url": "https://s3.example.com"}, u)
+	golden.AssertRoundTrip(t, fixture, u)
+}
+
+func TestContract_GetS3UserInfo(t *testing.T) {
+	a, fixture := golden.Server(t, "s3_user.json")
+
+	info, err := NewClient(a).GetS3UserInfo(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, S3UserInfo{
+		DisplayName:   "John Doe",
+		Email:         "john@example.com",
+		MaxBuckets:    1000,
+		S3Credentials: goldenKeys[:1],
+		UserID:        "john",
+	}, info)
+	golden.AssertRoundTrip(t, fixture, info)
+}
+
+func TestContract_GetS3UserKeys(t *testing.T) {
+	a, fixture := golden.Server(t, "s3_keys.json")
+
+	keys, err := NewClient(a).GetS3UserKeys(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, goldenKeys, keys)
+	golden.AssertRoundTrip(t, fixture, keys)
+}
+
+func TestContract_GenerateS3UserKey(t *testing.T) {
+	a, _ := golden.Server(t, "s3_keys.json")
+
+	keys, err := NewClient(a).GenerateS3UserKey(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, goldenKeys, keys)
+}
+
+func TestContract_GetBucket(t *testing.T) {
+	a, fixture := golden.Server(t, "bucket.json")
+
+	b, err := NewClient(a).GetBucket(context.Background(), "assets")
+	assert.NoError(t, err)
+	assert.Equal(t, goldenBucket, b)
+	golden.AssertRoundTrip(t, fixture, b)
+}
+
+func TestContract_CreateBucket(t *testing.T) {
+	a, _ := golden.Server(t, "bucket.json")
+
+	b, err := NewClient(a).CreateBucket(context.Background(), "assets")
+	assert.NoError(t, err)
+	assert.Equal(t, goldenBucket, b)
+}
+
+func TestContract_ListBuckets(t *testing.T) {
+	a, fixture := golden.Server(t, "buckets.json")
+
+	buckets, err := NewClient(a).ListBuckets(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []S3Bucket{goldenBucket, {
+		Name:             "backups",
+		BillingAccountID: 5678,
+		CreatedAt:        "2023-04-04 04:04:04",
+		ModifiedAt:       "2023-04-05 05:05:05",
+		IsSuspended:      true,
+	}}, buckets)
+	golden.AssertRoundTrip(t, fixture, buckets)
+}
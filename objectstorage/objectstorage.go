@@ -2,7 +2,6 @@ package objectstorage
 
 import (
 	"context"
-	"encoding/json"
 	"net/url"
 	"strconv"
 
@@ -15,182 +14,208 @@ func NewClient(client *api.API) *Client {
 	}
 }
 
-// ForBillingAccount set the value of BillingAccountID
+// WithBillingAccount returns copy of the client scoped to given billing account
+func (c *Client) WithBillingAccount(id int) *Client {
+	cc := *c
+	cc.BillingAccountID = id
+	return &cc
+}
+
+// ForBillingAccount returns copy of the client scoped to given billing account.
+//
+// Deprecated: use WithBillingAccount, the client is no longer modified.
 func (c *Client) ForBillingAccount(id int) *Client {
-	c.BillingAccountID = id
-	return c
+	return c.WithBillingAccount(id)
+}
+
+// billingAccount returns billing account of the call, which is the client billing account unless overridden.
+// Object storage isn't location-scoped, so location can't be overridden.
+func (c *Client) billingAccount(opts []api.CallOption) (int, error) {
+	s, err := api.Scope{BillingAccountID: c.BillingAccountID}.Resolve(opts, api.ScopeBillingAccount)
+	return s.BillingAccountID, err
 }
 
 // GetS3ApiURL https://api.warren.io/#s3-api-info
-func (c *Client) GetS3ApiURL(ctx context.Context) (map[string]string, error) {
-	rc := api.RequestConfig{
-		Method: "GET",
-		Path:   "/v1/storage/api/s3",
-	}
-	resp := c.API.FormRequest(ctx, rc)
-	if resp.Error != nil {
-		return nil, resp.Error
-	}
-	var data map[string]string
-	if err := json.Unmarshal(resp.Body, &data); err != nil {
+func (c *Client) GetS3ApiURL(ctx context.Context, opts ...api.CallOption) (map[string]string, error) {
+	if err := api.CheckOptions(opts, 0); err != nil {
 		return nil, err
 	}
-	return data, nil
+	rc := api.RequestConfig{
+		Method:    "GET",
+		Path:      "/v1/storage/api/s3",
+		Operation: "objectstorage.GetS3ApiURL",
+	}
+	return api.Do[map[string]string](ctx, c.API, rc)
 }
 
 // GetS3UserInfo https://api.warren.io/#get-s3-user
-func (c *Client) GetS3UserInfo(ctx context.Context) (S3UserInfo, error) {
-	var info S3UserInfo
-	rc := api.RequestConfig{
-		Method: "GET",
-		Path:   "/v1/storage/user",
+func (c *Client) GetS3UserInfo(ctx context.Context, opts ...api.CallOption) (S3UserInfo, error) {
+	if err := api.CheckOptions(opts, 0); err != nil {
+		return S3UserInfo{}, err
 	}
-	resp := c.API.FormRequest(ctx, rc)
-	if resp.Error != nil {
-		return info, resp.Error
-	}
-	if err := json.Unmarshal(resp.Body, &info); err != nil {
-		return info, err
+	rc := api.RequestConfig{
+		Method:    "GET",
+		Path:      "/v1/storage/user",
+		Operation: "objectstorage.GetS3UserInfo",
 	}
-	return info, nil
+	return api.Do[S3UserInfo](ctx, c.API, rc)
 }
 
 // GetS3UserKeys https://api.warren.io/#get-keys
-func (c *Client) GetS3UserKeys(ctx context.Context) ([]S3Credential, error) {
-	rc := api.RequestConfig{
-		Method: "GET",
-		Path:   "/v1/storage/user/keys",
-	}
-	resp := c.API.FormRequest(ctx, rc)
-	if resp.Error != nil {
-		return nil, resp.Error
-	}
-	var credentials []S3Credential
-	if err := json.Unmarshal(resp.Body, &credentials); err != nil {
+func (c *Client) GetS3UserKeys(ctx context.Context, opts ...api.CallOption) ([]S3Credential, error) {
+	if err := api.CheckOptions(opts, 0); err != nil {
 		return nil, err
 	}
-	return credentials, nil
+	rc := api.RequestConfig{
+		Method:    "GET",
+		Path:      "/v1/storage/user/keys",
+		Operation: "objectstorage.GetS3UserKeys",
+	}
+	return api.Do[[]S3Credential](ctx, c.API, rc)
 }
 
 // GenerateS3UserKey https://api.warren.io/#generate-key
-func (c *Client) GenerateS3UserKey(ctx context.Context) ([]S3Credential, error) {
-	rc := api.RequestConfig{
-		Method: "POST",
-		Path:   "/v1/storage/user/keys",
-	}
-	resp := c.API.FormRequest(ctx, rc)
-	if resp.Error != nil {
-		return nil, resp.Error
-	}
-	var credentials []S3Credential
-	if err := json.Unmarshal(resp.Body, &credentials); err != nil {
+func (c *Client) GenerateS3UserKey(ctx context.Context, opts ...api.CallOption) ([]S3Credential, error) {
+	if err := api.CheckOptions(opts, 0); err != nil {
 		return nil, err
 	}
-	return credentials, nil
+	rc := api.RequestConfig{
+		Method:    "POST",
+		Path:      "/v1/storage/user/keys",
+		Operation: "objectstorage.GenerateS3UserKey",
+	}
+	return api.Do[[]S3Credential](ctx, c.API, rc)
 }
 
 // DeleteS3UserKey https://api.warren.io/#generate-key
-func (c *Client) DeleteS3UserKey(ctx context.Context, accessKey string) error {
+func (c *Client) DeleteS3UserKey(ctx context.Context, accessKey string, opts ...api.CallOption) error {
+	if err := api.CheckOptions(opts, 0); err != nil {
+		return err
+	}
 	rc := api.RequestConfig{
-		Method: "DELETE",
-		Path:   "/v1/storage/user/keys",
-		Query:  url.Values{"access_key": []string{accessKey}},
+		Method:     "DELETE",
+		Path:       "/v1/storage/user/keys",
+		Query:      url.Values{"access_key": []string{accessKey}},
+		Operation:  "objectstorage.DeleteS3UserKey",
+		ResourceID: accessKey,
 	}
-	return c.API.FormRequest(ctx, rc).Error
+	return api.DoNoContent(ctx, c.API, rc)
 }
 
 // ListBuckets https://api.warren.io/#list-buckets
-func (c *Client) ListBuckets(ctx context.Context) ([]S3Bucket, error) {
-	var resp api.ClientResponse
-
-	if c.BillingAccountID == 0 {
-		rc := api.RequestConfig{
-			Method: "GET",
-			Path:   "/v1/storage/bucket/list",
-		}
-		resp = c.API.FormRequest(ctx, rc)
-	} else {
-		rc := api.RequestConfig{
-			Method: "GET",
-			Path:   "/v1/storage/bucket/list",
-			Query:  url.Values{"billing_account_id": []string{strconv.Itoa(c.BillingAccountID)}},
-		}
-		resp = c.API.FormRequest(ctx, rc)
-	}
-
-	if resp.Error != nil {
-		return nil, resp.Error
-	}
-	var buckets []S3Bucket
-	if err := json.Unmarshal(resp.Body, &buckets); err != nil {
+func (c *Client) ListBuckets(ctx context.Context, opts ...api.CallOption) ([]S3Bucket, error) {
+	rc := api.RequestConfig{
+		Method:    "GET",
+		Path:      "/v1/storage/bucket/list",
+		Operation: "objectstorage.ListBuckets",
+	}
+	id, err := c.billingAccount(opts)
+	if err != nil {
 		return nil, err
 	}
-	return buckets, nil
+	if id != 0 {
+		rc.Query = url.Values{"billing_account_id": []string{strconv.Itoa(id)}}
+	}
+	return api.Do[[]S3Bucket](ctx, c.API, rc)
 }
 
 // GetBucket https://api.warren.io/#get-bucket
-func (c *Client) GetBucket(ctx context.Context, bucketName string) (S3Bucket, error) {
-	var bucket S3Bucket
-	rc := api.RequestConfig{
-		Method: "GET",
-		Path:   "/v1/storage/bucket",
-		Query:  url.Values{"name": []string{bucketName}},
-	}
-	resp := c.API.FormRequest(ctx, rc)
-	if resp.Error != nil {
-		return bucket, resp.Error
+func (c *Client) GetBucket(ctx context.Context, bucketName string, opts ...api.CallOption) (S3Bucket, error) {
+	if err := api.CheckOptions(opts, 0); err != nil {
+		return S3Bucket{}, err
 	}
-
-	if err := json.Unmarshal(resp.Body, &bucket); err != nil {
-		return bucket, err
+	rc := api.RequestConfig{
+		Method:     "GET",
+		Path:       "/v1/storage/bucket",
+		Query:      url.Values{"name": []string{bucketName}},
+		Operation:  "objectstorage.GetBucket",
+		ResourceID: bucketName,
 	}
-	return bucket, nil
+	return api.Do[S3Bucket](ctx, c.API, rc)
 }
 
 // CreateBucket https://api.warren.io/#create-bucket
-func (c *Client) CreateBucket(ctx context.Context, bucketName string) (S3Bucket, error) {
+func (c *Client) CreateBucket(ctx context.Context, bucketName string, opts ...api.CallOption) (S3Bucket, error) {
+	return c.createBucket(ctx, bucketName, "", opts)
+}
+
+func (c *Client) createBucket(ctx context.Context, bucketName, idempotencyKey string, opts []api.CallOption) (S3Bucket, error) {
+	id, err := c.billingAccount(opts)
+	if err != nil {
+		return S3Bucket{}, err
+	}
 	d := url.Values{"name": []string{bucketName}}
-	if c.BillingAccountID != 0 {
-		d.Add("billing_account_id", strconv.Itoa(c.BillingAccountID))
+	if id != 0 {
+		d.Add("billing_account_id", strconv.Itoa(id))
 	}
 
-	var bucket S3Bucket
 	rc := api.RequestConfig{
-		Method: "PUT",
-		Path:   "/v1/storage/bucket",
-		Data:   d,
+		Method:         "PUT",
+		Path:           "/v1/storage/bucket",
+		Data:           d,
+		IdempotencyKey: idempotencyKey,
+		Operation:      "objectstorage.CreateBucket",
+		ResourceID:     bucketName,
+	}
+	return api.Do[S3Bucket](ctx, c.API, rc)
+}
+
+// EnsureBucket creates bucket unless it already exists, see api.NewIdempotencyKey
+func (c *Client) EnsureBucket(ctx context.Context, bucketName string, opts ...api.CallOption) (S3Bucket, bool, error) {
+	id, err := c.billingAccount(opts)
+	if err != nil {
+		return S3Bucket{}, false, err
 	}
-	resp := c.API.FormRequest(ctx, rc)
-	if resp.Error != nil {
-		return bucket, resp.Error
+	b, err := c.GetBucket(ctx, bucketName)
+	if err == nil || !api.IsNotFound(err) {
+		return b, false, err
 	}
-	if err := json.Unmarshal(resp.Body, &bucket); err != nil {
-		return bucket, err
+
+	b, err = c.WithBillingAccount(id).createBucket(ctx, bucketName, api.NewIdempotencyKey(), nil)
+	if err == nil {
+		return b, true, nil
+	}
+	if !api.MayHaveSucceeded(err) && !api.IsConflict(err) {
+		return S3Bucket{}, false, err
+	}
+	found, lerr := c.GetBucket(ctx, bucketName)
+	if lerr != nil {
+		return S3Bucket{}, false, err
 	}
-	return bucket, nil
+	return found, !api.IsConflict(err), nil
 }
 
 // DeleteBucket https://api.warren.io/#delete-bucket
-func (c *Client) DeleteBucket(ctx context.Context, bucketName string) error {
+func (c *Client) DeleteBucket(ctx context.Context, bucketName string, opts ...api.CallOption) error {
+	if err := api.CheckOptions(opts, 0); err != nil {
+		return err
+	}
 	rc := api.RequestConfig{
-		Method: "DELETE",
-		Path:   "/v1/storage/bucket",
-		Query:  url.Values{"name": []string{bucketName}},
+		Method:     "DELETE",
+		Path:       "/v1/storage/bucket",
+		Query:      url.Values{"name": []string{bucketName}},
+		Operation:  "objectstorage.DeleteBucket",
+		ResourceID: bucketName,
 	}
-	return c.API.FormRequest(ctx, rc).Error
+	return api.DoNoContent(ctx, c.API, rc)
 }
 
 // UpdateBucketBillingAccount https://api.warren.io/#modify-bucket
-func (c *Client) UpdateBucketBillingAccount(ctx context.Context, bucketName string, billingAccountID int) error {
+func (c *Client) UpdateBucketBillingAccount(ctx context.Context, bucketName string, billingAccountID int, opts ...api.CallOption) error {
+	if err := api.CheckOptions(opts, 0); err != nil {
+		return err
+	}
 	d := url.Values{
 		"name":               []string{bucketName},
 		"billing_account_id": []string{strconv.Itoa(billingAccountID)},
 	}
 
 	rc := api.RequestConfig{
-		Method: "PATCH",
-		Path:   "/v1/storage/bucket",
-		Data:   d,
+		Method:     "PATCH",
+		Path:       "/v1/storage/bucket",
+		Data:       d,
+		Operation:  "objectstorage.UpdateBucketBillingAccount",
+		ResourceID: bucketName,
 	}
-	return c.API.FormRequest(ctx, rc).Error
+	return api.DoNoContent(ctx, c.API, rc)
 }
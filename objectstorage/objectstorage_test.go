@@ -2,16 +2,95 @@ package objectstorage
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strconv"
 	"testing"
 
 	"github.com/ekaputra07/warren-go/api"
+	"github.com/ekaputra07/warren-go/internal/scopetest"
 	"github.com/stretchr/testify/assert"
 )
 
-func ForBillingAccount(t *testing.T) {
-	c := NewClient(api.Default).ForBillingAccount(123)
-	assert.Equal(t, 123, c.BillingAccountID)
+func TestWithBillingAccount(t *testing.T) {
+	c := NewClient(api.Default)
+	scoped := c.WithBillingAccount(123)
+	assert.Equal(t, 123, scoped.BillingAccountID)
+	assert.Equal(t, 0, c.BillingAccountID)
+	assert.Same(t, c.API, scoped.API)
+
+	assert.Equal(t, 456, c.ForBillingAccount(456).BillingAccountID)
+	assert.Equal(t, 0, c.BillingAccountID)
+}
+
+func TestListBuckets_ForBillingAccount(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/storage/bucket/list?billing_account_id=2", r.RequestURI)
+	})
+	defer s.Close()
+
+	os := Client{API: a, BillingAccountID: 1}
+	_, err := os.ListBuckets(context.Background(), api.ForBillingAccount(2))
+	assert.NoError(t, err)
+}
+
+func TestEnsureBucket_ForBillingAccount(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			assert.Equal(t, "/v1/storage/bucket?name=b", r.RequestURI)
+			w.WriteHeader(http.StatusNotFound)
+		case "PUT":
+			r.ParseForm()
+			assert.Equal(t, "2", r.PostForm.Get("billing_account_id"))
+			w.Write([]byte(`{"name": "b", "billing_account_id": 2}`))
+		}
+	})
+	defer s.Close()
+
+	os := Client{API: a, BillingAccountID: 1}
+	b, created, err := os.EnsureBucket(context.Background(), "b", api.ForBillingAccount(2))
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, 2, b.BillingAccountID)
+}
+
+func TestGetBucket_UnsupportedOption(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request must not be sent")
+	})
+	defer s.Close()
+
+	os := Client{API: a}
+	_, err := os.GetBucket(context.Background(), "b", api.ForBillingAccount(2))
+	assert.ErrorIs(t, err, api.ErrUnsupportedOption)
+	_, err = os.ListBuckets(context.Background(), api.InLocation("jkt01"))
+	assert.ErrorIs(t, err, api.ErrUnsupportedOption)
+}
+
+// run with -race to verify a shared client is safe to use with different scopes
+func TestClient_Concurrent(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(fmt.Sprintf(`[{"name": %q}]`, r.URL.Query().Get("billing_account_id"))))
+	})
+	defer s.Close()
+	c := NewClient(a).WithBillingAccount(1)
+
+	check := func(s api.Scope, buckets []S3Bucket, err error) {
+		assert.NoError(t, err)
+		assert.Equal(t, strconv.Itoa(s.BillingAccountID), buckets[0].Name)
+	}
+	scopetest.Concurrent(
+		func(s api.Scope) {
+			buckets, err := c.WithBillingAccount(s.BillingAccountID).ListBuckets(context.Background())
+			check(s, buckets, err)
+		},
+		func(s api.Scope) {
+			buckets, err := c.ListBuckets(context.Background(), api.ForBillingAccount(s.BillingAccountID))
+			check(s, buckets, err)
+		},
+	)
+	assert.Equal(t, 1, c.BillingAccountID)
 }
 
 func TestGetS3ApiURL(t *testing.T) {
@@ -158,3 +237,40 @@ func TestUpdateBucketBillingAccount(t *testing.T) {
 	os := Client{API: a}
 	os.UpdateBucketBillingAccount(context.Background(), "testBucket", 123)
 }
+
+func TestListBuckets_Unauthorized(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	defer s.Close()
+
+	os := Client{API: a}
+	_, err := os.ListBuckets(context.Background())
+	assert.True(t, api.IsUnauthorized(err))
+}
+
+func TestEnsureBucket_Conflict(t *testing.T) {
+	gets := 0
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			gets++
+			// created by someone else between the lookup and the create
+			if gets == 1 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(`{"name": "b"}`))
+		case "PUT":
+			w.WriteHeader(http.StatusConflict)
+		}
+	})
+	defer s.Close()
+
+	os := Client{API: a}
+	b, created, err := os.EnsureBucket(context.Background(), "b")
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, "b", b.Name)
+	assert.Equal(t, 2, gets)
+}
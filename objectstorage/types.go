@@ -1,6 +1,10 @@
 package objectstorage
 
-import "github.com/ekaputra07/warren-go/api"
+import (
+	"context"
+
+	"github.com/ekaputra07/warren-go/api"
+)
 
 // S3Bucket represents Object Storage bucket
 type S3Bucket struct {
@@ -23,15 +27,36 @@ type S3Credential struct {
 // S3UserInfo holds some information about user
 // `caps`, `subusers` and `swiftCredentials` are ommited (documentation not clear)
 type S3UserInfo struct {
-	DisplayName   string
-	Email         string
-	MaxBuckets    int
-	S3Credentials []S3Credential
-	Suspended     int
-	UserID        string
+	DisplayName   string         `json:"displayName"`
+	Email         string         `json:"email"`
+	MaxBuckets    int            `json:"maxBuckets"`
+	S3Credentials []S3Credential `json:"s3Credentials"`
+	Suspended     int            `json:"suspended"`
+	UserID        string         `json:"userId"`
+}
+
+// Service is implemented by Client, use it in place of *Client to be able to mock the API.
+// Every method accepts call options to override scope of the client for that single call,
+// options that don't apply to the method are rejected with api.ErrUnsupportedOption.
+type Service interface {
+	GetS3ApiURL(ctx context.Context, opts ...api.CallOption) (map[string]string, error)
+	GetS3UserInfo(ctx context.Context, opts ...api.CallOption) (S3UserInfo, error)
+	GetS3UserKeys(ctx context.Context, opts ...api.CallOption) ([]S3Credential, error)
+	GenerateS3UserKey(ctx context.Context, opts ...api.CallOption) ([]S3Credential, error)
+	DeleteS3UserKey(ctx context.Context, accessKey string, opts ...api.CallOption) error
+	ListBuckets(ctx context.Context, opts ...api.CallOption) ([]S3Bucket, error)
+	GetBucket(ctx context.Context, bucketName string, opts ...api.CallOption) (S3Bucket, error)
+	CreateBucket(ctx context.Context, bucketName string, opts ...api.CallOption) (S3Bucket, error)
+	EnsureBucket(ctx context.Context, bucketName string, opts ...api.CallOption) (S3Bucket, bool, error)
+	DeleteBucket(ctx context.Context, bucketName string, opts ...api.CallOption) error
+	UpdateBucketBillingAccount(ctx context.Context, bucketName string, billingAccountID int, opts ...api.CallOption) error
 }
 
-// Client is object storage client
+var _ Service = (*Client)(nil)
+
+// Client is object storage client, it's safe for concurrent use.
+// Use WithBillingAccount or api.ForBillingAccount call option instead of modifying BillingAccountID of a shared client.
+// Billing account is used by the methods listing and creating buckets only.
 type Client struct {
 	BillingAccountID int
 	API              *api.API
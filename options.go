@@ -0,0 +1,259 @@
+package warren
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/ekaputra07/warren-go/api"
+)
+
+// Option configures Warren created by NewClient
+type Option func(*options) error
+
+type options struct {
+	baseURL          string
+	apiKey           string
+	httpClient       *http.Client
+	location         string
+	billingAccountID int
+	userAgent        string
+	timeout          time.Duration
+	dryRun           *api.Plan
+	cache            *api.Cache
+	circuitBreaker   *api.CircuitBreaker
+	idempotency      string
+	retryPolicy      api.RetryPolicy
+	rateLimiter      *api.RateLimiter
+	logger           *slog.Logger
+	tracing          *api.Tracing
+	strictDecoding   bool
+	middlewares      []api.Middleware
+}
+
+// WithBaseURL sets API base URL, default to WARREN_API_BASE_URL environment variable.
+func WithBaseURL(baseURL string) Option {
+	return func(o *options) error {
+		o.baseURL = baseURL
+		return nil
+	}
+}
+
+// WithAPIKey sets API key, default to WARREN_API_KEY environment variable.
+func WithAPIKey(key string) Option {
+	return func(o *options) error {
+		o.apiKey = key
+		return nil
+	}
+}
+
+// WithHTTPClient sets HTTP client used to make requests, default to http.DefaultClient.
+func WithHTTPClient(c *http.Client) Option {
+	return func(o *options) error {
+		if c == nil {
+			return errors.New("http client must not be nil")
+		}
+		o.httpClient = c
+		return nil
+	}
+}
+
+// WithLocation sets data center location required by location-scoped clients such as VPC, IP and VM.
+func WithLocation(location string) Option {
+	return func(o *options) error {
+		if location == "" {
+			return errors.New("location must not be empty")
+		}
+		o.location = location
+		return nil
+	}
+}
+
+// WithBillingAccount sets default billing account used by clients that support it: ObjectStorage, BlockStorage, IP and VM.
+func WithBillingAccount(id int) Option {
+	return func(o *options) error {
+		if id <= 0 {
+			return fmt.Errorf("billing account ID with value of %v is invalid", id)
+		}
+		o.billingAccountID = id
+		return nil
+	}
+}
+
+// WithUserAgent sets `User-Agent` header sent with every request.
+func WithUserAgent(ua string) Option {
+	return func(o *options) error {
+		o.userAgent = ua
+		return nil
+	}
+}
+
+// WithTimeout sets timeout of every HTTP request.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) error {
+		if d <= 0 {
+			return fmt.Errorf("timeout with value of %v is invalid", d)
+		}
+		o.timeout = d
+		return nil
+	}
+}
+
+// WithDryRun enables dry-run mode, mutating calls are recorded into plan and return api.ErrDryRun.
+func WithDryRun(plan *api.Plan) Option {
+	return func(o *options) error {
+		if plan == nil {
+			return errors.New("plan must not be nil")
+		}
+		o.dryRun = plan
+		return nil
+	}
+}
+
+// WithCache enables caching of GET responses, see api.NewCache.
+func WithCache(c *api.Cache) Option {
+	return func(o *options) error {
+		if c == nil {
+			return errors.New("cache must not be nil")
+		}
+		o.cache = c
+		return nil
+	}
+}
+
+// WithCircuitBreaker enables circuit breaker, see api.NewCircuitBreaker.
+func WithCircuitBreaker(b *api.CircuitBreaker) Option {
+	return func(o *options) error {
+		if b == nil {
+			return errors.New("circuit breaker must not be nil")
+		}
+		o.circuitBreaker = b
+		return nil
+	}
+}
+
+// WithIdempotencyHeader sets header used to send idempotency key of create requests,
+// use it only when the provider supports idempotent requests (e.g. api.IdempotencyKeyHeader).
+func WithIdempotencyHeader(name string) Option {
+	return func(o *options) error {
+		if name == "" {
+			return errors.New("idempotency header must not be empty")
+		}
+		o.idempotency = name
+		return nil
+	}
+}
+
+// WithRetryPolicy sets policy used to retry failed requests, e.g. api.DefaultBackoff().
+func WithRetryPolicy(p api.RetryPolicy) Option {
+	return func(o *options) error {
+		if p == nil {
+			return errors.New("retry policy must not be nil")
+		}
+		o.retryPolicy = p
+		return nil
+	}
+}
+
+// WithRateLimiter throttles outgoing requests, see api.NewRateLimiter.
+func WithRateLimiter(l *api.RateLimiter) Option {
+	return func(o *options) error {
+		if l == nil {
+			return errors.New("rate limiter must not be nil")
+		}
+		o.rateLimiter = l
+		return nil
+	}
+}
+
+// WithLogger enables debug logging of all requests, secrets are always redacted.
+func WithLogger(l *slog.Logger) Option {
+	return func(o *options) error {
+		if l == nil {
+			return errors.New("logger must not be nil")
+		}
+		o.logger = l
+		return nil
+	}
+}
+
+// WithTracing enables OpenTelemetry span for every call.
+func WithTracing(t *api.Tracing) Option {
+	return func(o *options) error {
+		if t == nil {
+			return errors.New("tracing must not be nil")
+		}
+		o.tracing = t
+		return nil
+	}
+}
+
+// WithStrictDecoding makes calls fail on response fields unknown to the library.
+func WithStrictDecoding() Option {
+	return func(o *options) error {
+		o.strictDecoding = true
+		return nil
+	}
+}
+
+// WithMiddleware appends middlewares to the chain wrapping every request, see api.API.Use.
+func WithMiddleware(mw ...api.Middleware) Option {
+	return func(o *options) error {
+		o.middlewares = append(o.middlewares, mw...)
+		return nil
+	}
+}
+
+// NewClient creates Warren configured with given options.
+// Base URL and API key are required, either from options or environment variables.
+// Without WithLocation, location-scoped clients (VPC, IP, VM) return api.ErrMissingLocation.
+// Every api.API setting has an option except Builtins, which replaces the authentication and status check
+// of every request, build the api.API yourself and use InitWithScope when you need it.
+func NewClient(opts ...Option) (*Warren, error) {
+	o := options{
+		baseURL:    os.Getenv(api.BaseURLEnvKey),
+		apiKey:     os.Getenv(api.APIKeyEnvKey),
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return nil, err
+		}
+	}
+
+	if o.baseURL == "" {
+		return nil, errors.New("base URL is required")
+	}
+	u, err := url.Parse(o.baseURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("base URL %q is invalid", o.baseURL)
+	}
+	if o.apiKey == "" {
+		return nil, errors.New("API key is required")
+	}
+
+	a := api.New(o.baseURL, o.apiKey)
+	a.UserAgent = o.userAgent
+	a.HTTPClient = o.httpClient
+	a.DryRun = o.dryRun
+	a.Cache = o.cache
+	a.CircuitBreaker = o.circuitBreaker
+	a.IdempotencyHeader = o.idempotency
+	a.RetryPolicy = o.retryPolicy
+	a.RateLimiter = o.rateLimiter
+	a.Tracing = o.tracing
+	a.StrictDecoding = o.strictDecoding
+	a.Logger = o.logger
+	a.Use(o.middlewares...)
+	if o.timeout > 0 {
+		c := *o.httpClient
+		c.Timeout = o.timeout
+		a.HTTPClient = &c
+	}
+
+	return InitWithScope(a, api.Scope{Location: o.location, BillingAccountID: o.billingAccountID}), nil
+}
@@ -0,0 +1,313 @@
+// Package recorder provides record/replay http.RoundTripper, so tests of code built on top of
+// this library can run offline against realistic Warren payloads.
+//
+// In record mode the requests are sent using the real transport and every request/response pair
+// is captured into a cassette file (the `apikey` header is scrubbed). In replay mode responses are
+// served from the cassette, matching requests on method, path and query (configurable).
+//
+//	rec, err := recorder.New("testdata/networks.yaml", recorder.ModeReplay)
+//	defer rec.Stop()
+//	a := api.New("https://api.idcloudhost.com", "secret")
+//	a.HTTPClient = rec.Client()
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Mode of the recorder
+type Mode int
+
+const (
+	// ModeReplay serves responses from cassette, the real transport is never used.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests using the real transport and captures them into cassette.
+	ModeRecord
+)
+
+// ErrNotRecorded is returned in replay mode when no recorded interaction matches the request
+var ErrNotRecorded = errors.New("recorder: interaction not recorded")
+
+// scrubbedHeaders are removed from recorded requests
+var scrubbedHeaders = []string{"apikey", "Authorization"}
+
+// Request is a recorded request
+type Request struct {
+	Method string      `json:"method" yaml:"method"`
+	Path   string      `json:"path" yaml:"path"`
+	Query  string      `json:"query,omitempty" yaml:"query,omitempty"`
+	Header http.Header `json:"header,omitempty" yaml:"header,omitempty"`
+	Body   string      `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+// Response is a recorded response
+type Response struct {
+	StatusCode int         `json:"status_code" yaml:"status_code"`
+	Header     http.Header `json:"header,omitempty" yaml:"header,omitempty"`
+	Body       string      `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+// Interaction is a single request/response pair
+type Interaction struct {
+	Request  Request  `json:"request" yaml:"request"`
+	Response Response `json:"response" yaml:"response"`
+}
+
+// Cassette holds recorded interactions
+type Cassette struct {
+	Interactions []Interaction `json:"interactions" yaml:"interactions"`
+}
+
+// LoadCassette reads cassette from path, format is json when path ends with `.json` otherwise yaml.
+func LoadCassette(path string) (*Cassette, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Cassette
+	if isJSON(path) {
+		err = json.Unmarshal(b, &c)
+	} else {
+		err = yaml.Unmarshal(b, &c)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid cassette %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Save writes cassette to path, format is json when path ends with `.json` otherwise yaml.
+func (c *Cassette) Save(path string) error {
+	var b []byte
+	var err error
+	if isJSON(path) {
+		b, err = json.MarshalIndent(c, "", "  ")
+	} else {
+		b, err = yaml.Marshal(c)
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+// Matcher reports whether incoming request (with its body) matches recorded request
+type Matcher func(r *http.Request, body []byte, rec Request) bool
+
+// MatchMethod matches request method
+func MatchMethod(r *http.Request, _ []byte, rec Request) bool {
+	return r.Method == rec.Method
+}
+
+// MatchPath matches request URL path
+func MatchPath(r *http.Request, _ []byte, rec Request) bool {
+	return r.URL.Path == rec.Path
+}
+
+// MatchQuery matches request query string, regardless the order of params
+func MatchQuery(r *http.Request, _ []byte, rec Request) bool {
+	return r.URL.Query().Encode() == sortedQuery(rec.Query)
+}
+
+// MatchBody matches request body
+func MatchBody(_ *http.Request, body []byte, rec Request) bool {
+	return string(body) == rec.Body
+}
+
+// DefaultMatchers are used when Recorder.Matchers is empty
+var DefaultMatchers = []Matcher{MatchMethod, MatchPath, MatchQuery}
+
+// Recorder is http.RoundTripper that records or replays interactions
+type Recorder struct {
+	// Transport is the real transport used in record mode, http.DefaultTransport if nil.
+	Transport http.RoundTripper
+	// Matchers used to find recorded interaction in replay mode, all of them must match.
+	Matchers []Matcher
+
+	mode     Mode
+	path     string
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// New creates Recorder for cassette in path. In replay mode the cassette must exist.
+func New(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{
+		mode:     mode,
+		path:     path,
+		cassette: &Cassette{},
+	}
+	if mode == ModeReplay {
+		c, err := LoadCassette(path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = c
+		r.used = make([]bool, len(c.Interactions))
+	}
+	return r, nil
+}
+
+// Client returns http.Client that uses the recorder as transport
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Stop saves the cassette in record mode
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette.Save(r.path)
+}
+
+// RoundTrip implements http.RoundTripper, the request is not modified.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, out, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.mode == ModeRecord {
+		return r.record(out, body)
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return r.replay(req, body)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	t := r.Transport
+	if t == nil {
+		t = http.DefaultTransport
+	}
+	res, err := t.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	b, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(b))
+
+	header := req.Header.Clone()
+	for _, h := range scrubbedHeaders {
+		header.Del(h)
+	}
+	i := Interaction{
+		Request: Request{
+			Method: req.Method,
+			Path:   req.URL.Path,
+			Query:  sortedQuery(req.URL.RawQuery),
+			Header: header,
+			Body:   string(body),
+		},
+		Response: Response{
+			StatusCode: res.StatusCode,
+			Header:     res.Header.Clone(),
+			Body:       string(b),
+		},
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, i)
+	r.mu.Unlock()
+	return res, nil
+}
+
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	matchers := r.Matchers
+	if len(matchers) == 0 {
+		matchers = DefaultMatchers
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for idx, i := range r.cassette.Interactions {
+		if r.used[idx] || !matchAll(matchers, req, body, i.Request) {
+			continue
+		}
+		r.used[idx] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", i.Response.StatusCode, http.StatusText(i.Response.StatusCode)),
+			StatusCode:    i.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        i.Response.Header.Clone(),
+			Body:          io.NopCloser(strings.NewReader(i.Response.Body)),
+			ContentLength: int64(len(i.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrNotRecorded, req.Method, req.URL.RequestURI())
+}
+
+func matchAll(matchers []Matcher, req *http.Request, body []byte, rec Request) bool {
+	for _, m := range matchers {
+		if !m(req, body, rec) {
+			return false
+		}
+	}
+	return true
+}
+
+// readRequestBody returns the request body and the request to send to the real transport.
+// The body is read from a copy (GetBody) when possible, otherwise it's consumed and the returned
+// request is a clone with the body restored, so the original request is never modified.
+func readRequestBody(req *http.Request) ([]byte, *http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, req, nil
+	}
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, nil, err
+		}
+		defer rc.Close()
+		b, err := io.ReadAll(rc)
+		return b, req, err
+	}
+	b, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(b))
+	out.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(b)), nil }
+	return b, out, nil
+}
+
+// sortedQuery normalizes query string so params order doesn't matter
+func sortedQuery(q string) string {
+	v, err := url.ParseQuery(q)
+	if err != nil {
+		return q
+	}
+	return v.Encode()
+}
+
+func isJSON(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".json")
+}
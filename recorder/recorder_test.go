@@ -0,0 +1,145 @@
+package recorder
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ekaputra07/warren-go/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func recordCassette(t *testing.T, path string) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/config/locations":
+			w.Write([]byte(`[{"slug": "jkt01"}]`))
+		case "/v1/storage/bucket":
+			_ = r.ParseForm()
+			w.Write([]byte(`{"name": "` + r.Form.Get("name") + `"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer s.Close()
+
+	rec, err := New(path, ModeRecord)
+	assert.NoError(t, err)
+	rec.Transport = s.Client().Transport
+
+	a := api.New(s.URL, "secret")
+	a.HTTPClient = rec.Client()
+
+	_, err = api.Do[[]map[string]any](context.Background(), a, api.RequestConfig{Method: "GET", Path: "/v1/config/locations"})
+	assert.NoError(t, err)
+	_, err = api.Do[map[string]any](context.Background(), a, api.RequestConfig{Method: "GET", Path: "/v1/storage/bucket", Query: map[string][]string{"name": {"a"}}})
+	assert.NoError(t, err)
+	_, err = api.Do[map[string]any](context.Background(), a, api.RequestConfig{Method: "GET", Path: "/v1/storage/bucket", Query: map[string][]string{"name": {"b"}}})
+	assert.NoError(t, err)
+	err = api.DoNoContent(context.Background(), a, api.RequestConfig{Method: "DELETE", Path: "/missing"})
+	assert.True(t, api.IsNotFound(err))
+
+	assert.NoError(t, rec.Stop())
+}
+
+func TestRecordReplay(t *testing.T) {
+	for _, name := range []string{"cassette.yaml", "cassette.json"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			recordCassette(t, path)
+
+			// apikey is scrubbed
+			b, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			assert.NotContains(t, string(b), "secret")
+
+			rec, err := New(path, ModeReplay)
+			assert.NoError(t, err)
+			a := api.New("http://warren.test", "other")
+			a.HTTPClient = rec.Client()
+
+			// served in any order
+			bucket, err := api.Do[map[string]any](context.Background(), a, api.RequestConfig{Method: "GET", Path: "/v1/storage/bucket", Query: map[string][]string{"name": {"b"}}})
+			assert.NoError(t, err)
+			assert.Equal(t, "b", bucket["name"])
+
+			locations, err := api.Do[[]map[string]any](context.Background(), a, api.RequestConfig{Method: "GET", Path: "/v1/config/locations"})
+			assert.NoError(t, err)
+			assert.Equal(t, "jkt01", locations[0]["slug"])
+
+			err = api.DoNoContent(context.Background(), a, api.RequestConfig{Method: "DELETE", Path: "/missing"})
+			assert.True(t, api.IsNotFound(err))
+
+			// each interaction served once
+			_, err = api.Do[[]map[string]any](context.Background(), a, api.RequestConfig{Method: "GET", Path: "/v1/config/locations"})
+			assert.ErrorIs(t, err, ErrNotRecorded)
+		})
+	}
+}
+
+func TestReplay_MatchBody(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+	c := &Cassette{Interactions: []Interaction{
+		{Request: Request{Method: "PUT", Path: "/v1/storage/bucket", Body: "name=a"}, Response: Response{StatusCode: 200, Body: `{"name": "a"}`}},
+		{Request: Request{Method: "PUT", Path: "/v1/storage/bucket", Body: "name=b"}, Response: Response{StatusCode: 200, Body: `{"name": "b"}`}},
+	}}
+	assert.NoError(t, c.Save(path))
+
+	rec, err := New(path, ModeReplay)
+	assert.NoError(t, err)
+	rec.Matchers = append(DefaultMatchers, MatchBody)
+
+	a := api.New("http://warren.test", "secret")
+	a.HTTPClient = rec.Client()
+
+	bucket, err := api.Do[map[string]any](context.Background(), a, api.RequestConfig{
+		Method: "PUT",
+		Path:   "/v1/storage/bucket",
+		Data:   map[string][]string{"name": {"b"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "b", bucket["name"])
+}
+
+func TestNew_MissingCassette(t *testing.T) {
+	_, err := New(filepath.Join(t.TempDir(), "missing.yaml"), ModeReplay)
+	assert.Error(t, err)
+}
+
+func TestRoundTrip_DoesNotModifyRequest(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		w.Write(b)
+	}))
+	defer s.Close()
+
+	rec, err := New(filepath.Join(t.TempDir(), "cassette.yaml"), ModeRecord)
+	assert.NoError(t, err)
+	rec.Transport = s.Client().Transport
+
+	// with GetBody
+	req, _ := http.NewRequest("POST", s.URL, strings.NewReader("name=a"))
+	body := req.Body
+	res, err := rec.RoundTrip(req)
+	assert.NoError(t, err)
+	b, _ := io.ReadAll(res.Body)
+	assert.Equal(t, "name=a", string(b))
+	assert.Equal(t, body, req.Body)
+
+	// without GetBody
+	req, _ = http.NewRequest("POST", s.URL, io.NopCloser(strings.NewReader("name=b")))
+	body = req.Body
+	res, err = rec.RoundTrip(req)
+	assert.NoError(t, err)
+	b, _ = io.ReadAll(res.Body)
+	assert.Equal(t, "name=b", string(b))
+	assert.Equal(t, body, req.Body)
+
+	assert.Equal(t, "name=a", rec.cassette.Interactions[0].Request.Body)
+	assert.Equal(t, "name=b", rec.cassette.Interactions[1].Request.Body)
+}
@@ -0,0 +1,107 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/ekaputra07/warren-go/api"
+	"github.com/google/uuid"
+)
+
+// BackupStatus is status of backup point
+type BackupStatus string
+
+const (
+	BackupStatusCreating  BackupStatus = "creating"
+	BackupStatusAvailable BackupStatus = "available"
+	BackupStatusFailed    BackupStatus = "failed"
+)
+
+// Backup is a backup point of VM taken by the provider while backups of the VM are enabled
+type Backup struct {
+	UUID      uuid.UUID    `json:"uuid"`
+	VMUUID    uuid.UUID    `json:"vm_uuid"`
+	Status    BackupStatus `json:"status"`
+	SizeGB    int          `json:"size"`
+	CreatedAt string       `json:"created_at"`
+}
+
+// EnableBackups https://api.warren.io/#vm-backups
+func (c *Client) EnableBackups(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (VM, error) {
+	return c.setBackups(ctx, id, true, opts)
+}
+
+// DisableBackups https://api.warren.io/#vm-backups
+// Existing backup points are kept.
+func (c *Client) DisableBackups(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (VM, error) {
+	return c.setBackups(ctx, id, false, opts)
+}
+
+func (c *Client) setBackups(ctx context.Context, id uuid.UUID, enabled bool, opts []api.CallOption) (VM, error) {
+	loc, err := c.location(opts)
+	if err != nil {
+		return VM{}, err
+	}
+	segment, op := "enable", "vm.EnableBackups"
+	if !enabled {
+		segment, op = "disable", "vm.DisableBackups"
+	}
+	rc := api.RequestConfig{
+		Method:     "POST",
+		Path:       fmt.Sprintf("/v1/%s/user-resource/vm/backups/%s", loc, segment),
+		Data:       url.Values{"uuid": []string{id.String()}},
+		Retryable:  true,
+		Operation:  op,
+		Location:   loc,
+		ResourceID: id.String(),
+	}
+	return api.Do[VM](ctx, c.API, rc)
+}
+
+// ListBackups https://api.warren.io/#list-vm-backups
+func (c *Client) ListBackups(ctx context.Context, id uuid.UUID, opts ...api.CallOption) ([]Backup, error) {
+	loc, err := c.location(opts)
+	if err != nil {
+		return nil, err
+	}
+	rc := api.RequestConfig{
+		Method:     "GET",
+		Path:       fmt.Sprintf("/v1/%s/user-resource/vm/backups", loc),
+		Query:      url.Values{"uuid": []string{id.String()}},
+		Operation:  "vm.ListBackups",
+		Location:   loc,
+		ResourceID: id.String(),
+	}
+	return api.Do[[]Backup](ctx, c.API, rc)
+}
+
+// RestoreBackup https://api.warren.io/#restore-vm-backup
+// The VM disks are replaced with the backup point, the VM must not be in transitional state.
+// To create a new VM from the backup instead, use CreateVM with CreateVMSpec.SourceBackup.
+func (c *Client) RestoreBackup(ctx context.Context, id, backupID uuid.UUID, opts ...api.CallOption) (VM, error) {
+	loc, err := c.location(opts)
+	if err != nil {
+		return VM{}, err
+	}
+	vm, err := c.GetVM(ctx, id, opts...)
+	if err != nil {
+		return VM{}, err
+	}
+	if vm.Status.Transitional() {
+		return vm, &StateError{UUID: id, Action: actionRestore, Status: vm.Status}
+	}
+
+	rc := api.RequestConfig{
+		Method: "POST",
+		Path:   fmt.Sprintf("/v1/%s/user-resource/vm/backups/restore", loc),
+		Data: url.Values{
+			"uuid":        []string{id.String()},
+			"backup_uuid": []string{backupID.String()},
+		},
+		Operation:  "vm.RestoreBackup",
+		Location:   loc,
+		ResourceID: id.String(),
+	}
+	return api.Do[VM](ctx, c.API, rc)
+}
@@ -0,0 +1,71 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ekaputra07/warren-go/api"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var backupID = uuid.MustParse("1f2e3d4c-5b6a-4798-8a7b-6c5d4e3f2a1b")
+
+func TestEnableBackups(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, fmt.Sprintf("/v1/%s/user-resource/vm/backups/enable", loc), r.RequestURI)
+		_ = r.ParseForm()
+		assert.Equal(t, id.String(), r.PostForm.Get("uuid"))
+	})
+	defer s.Close()
+
+	vm := Client{API: a, Location: loc}
+	vm.EnableBackups(context.Background(), id)
+}
+
+func TestDisableBackups(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, fmt.Sprintf("/v1/%s/user-resource/vm/backups/disable", loc), r.RequestURI)
+	})
+	defer s.Close()
+
+	vm := Client{API: a, Location: loc}
+	vm.DisableBackups(context.Background(), id)
+}
+
+func TestListBackups(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, fmt.Sprintf("/v1/%s/user-resource/vm/backups?uuid=%s", loc, id), r.RequestURI)
+	})
+	defer s.Close()
+
+	vm := Client{API: a, Location: loc}
+	vm.ListBackups(context.Background(), id)
+}
+
+func TestRestoreBackup(t *testing.T) {
+	a := vmServer(t, func() Status { return StatusStopped }, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, fmt.Sprintf("/v1/%s/user-resource/vm/backups/restore", loc), r.RequestURI)
+		_ = r.ParseForm()
+		assert.Equal(t, id.String(), r.PostForm.Get("uuid"))
+		assert.Equal(t, backupID.String(), r.PostForm.Get("backup_uuid"))
+	})
+
+	_, err := NewClient(a, loc).RestoreBackup(context.Background(), id, backupID)
+	assert.NoError(t, err)
+}
+
+func TestRestoreBackup_Transitional(t *testing.T) {
+	a := vmServer(t, func() Status { return StatusStarting }, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("restore must not be sent")
+	})
+
+	_, err := NewClient(a, loc).RestoreBackup(context.Background(), id, backupID)
+	assert.ErrorIs(t, err, ErrTransitioning)
+}
@@ -0,0 +1,120 @@
+package vm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ekaputra07/warren-go/internal/golden"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var goldenVM = VM{
+	ID:          3051,
+	UUID:        id,
+	Name:        "web-1",
+	Hostname:    "web-1",
+	Description: "frontend",
+	Status:      StatusRunning,
+	VCPU:        2,
+	MemoryMB:    4096,
+	OSName:      "ubuntu",
+	OSVersion:   "22.04",
+	Storage: []Storage{{
+		ID:        8812,
+		UUID:      uuid.MustParse("3c4d5e6f-7a8b-4c9d-8e0f-1a2b3c4d5e6f"),
+		Name:      "web-1-boot",
+		Pool:      "default2",
+		Type:      "block",
+		Primary:   true,
+		SizeGB:    40,
+		UserID:    1201,
+		CreatedAt: "2023-04-02 08:15:00",
+		UpdatedAt: "2023-04-02 08:15:00",
+	}},
+	MAC:              "52:54:00:1a:2b:3c",
+	PrivateIPv4:      "10.42.0.12",
+	PublicIPv4:       "185.12.5.40",
+	PublicIPv6:       "2a0e:c00:0:412::12",
+	NetworkUUID:      uuid.MustParse("5e2f8c1a-7d4b-4a9e-b3c6-1f0d9e8a7b52"),
+	BillingAccountID: 1042,
+	UserID:           1201,
+	Username:         "jane@example.com",
+	CreatedAt:        "2023-04-02 08:15:00",
+	UpdatedAt:        "2023-05-10 17:20:45",
+}
+
+func TestContract_GetVM(t *testing.T) {
+	a, fixture := golden.Server(t, "vm.json")
+
+	v, err := NewClient(a, loc).GetVM(context.Background(), id)
+	assert.NoError(t, err)
+	assert.Equal(t, goldenVM, v)
+	golden.AssertRoundTrip(t, fixture, v)
+}
+
+func TestContract_ListVMs(t *testing.T) {
+	a, fixture := golden.Server(t, "vms.json")
+
+	vms, err := NewClient(a, loc).ListVMs(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []VM{goldenVM, {
+		ID:               3052,
+		UUID:             uuid.MustParse("8c0f5d3b-2e4a-4f6b-9c7d-0e1f2a3b4c5d"),
+		Name:             "db-1",
+		Hostname:         "db-1",
+		Status:           StatusStopped,
+		VCPU:             4,
+		MemoryMB:         8192,
+		OSName:           "debian",
+		OSVersion:        "12",
+		Storage:          []Storage{},
+		MAC:              "52:54:00:4d:5e:6f",
+		PrivateIPv4:      "10.42.0.13",
+		NetworkUUID:      uuid.MustParse("5e2f8c1a-7d4b-4a9e-b3c6-1f0d9e8a7b52"),
+		Backup:           true,
+		BillingAccountID: 1042,
+		UserID:           1201,
+		Username:         "jane@example.com",
+		CreatedAt:        "2023-04-03 10:00:00",
+		UpdatedAt:        "2023-04-03 10:00:00",
+	}}, vms)
+	golden.AssertRoundTrip(t, fixture, vms)
+}
+
+func TestContract_CreateVM(t *testing.T) {
+	a, _ := golden.Server(t, "vm.json")
+
+	v, err := NewClient(a, loc).CreateVM(context.Background(), spec)
+	assert.NoError(t, err)
+	assert.Equal(t, goldenVM, v)
+}
+
+func TestContract_GetLimits(t *testing.T) {
+	a, fixture := golden.Server(t, "limits.json")
+
+	l, err := NewClient(a, loc).GetLimits(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, Limits{MinVCPU: 1, MaxVCPU: 16, MinMemoryMB: 512, MaxMemoryMB: 65536}, l)
+	golden.AssertRoundTrip(t, fixture, l)
+}
+
+func TestContract_ListBackups(t *testing.T) {
+	a, fixture := golden.Server(t, "backups.json")
+
+	backups, err := NewClient(a, loc).ListBackups(context.Background(), id)
+	assert.NoError(t, err)
+	assert.Equal(t, []Backup{{
+		UUID:      backupID,
+		VMUUID:    id,
+		Status:    BackupStatusAvailable,
+		SizeGB:    40,
+		CreatedAt: "2023-05-09 02:00:00",
+	}, {
+		UUID:      uuid.MustParse("2a3b4c5d-6e7f-4a8b-9c0d-1e2f3a4b5c6d"),
+		VMUUID:    id,
+		Status:    BackupStatusCreating,
+		CreatedAt: "2023-05-10 02:00:00",
+	}}, backups)
+	golden.AssertRoundTrip(t, fixture, backups)
+}
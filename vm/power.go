@@ -0,0 +1,239 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/ekaputra07/warren-go/api"
+	"github.com/ekaputra07/warren-go/wait"
+	"github.com/google/uuid"
+)
+
+// DefaultPollInterval is how often the VM is polled while waiting for its status
+const DefaultPollInterval = 5 * time.Second
+
+// Action is power action performed on VM
+type Action string
+
+const (
+	ActionStart     Action = "start"
+	ActionStop      Action = "stop"
+	ActionReboot    Action = "reboot"
+	ActionForceStop Action = "force-stop"
+
+	// actionDelete, actionResize and actionRestore are only used in StateError returned by
+	// Delete, Resize and RestoreBackup
+	actionDelete  Action = "delete"
+	actionResize  Action = "resize"
+	actionRestore Action = "restore"
+)
+
+// valid reports whether the action can be performed by PerformAction
+func (a Action) valid() bool {
+	switch a {
+	case ActionStart, ActionStop, ActionReboot, ActionForceStop:
+		return true
+	}
+	return false
+}
+
+// target returns status of VM after the action
+func (a Action) target() Status {
+	switch a {
+	case ActionStop, ActionForceStop:
+		return StatusStopped
+	case actionDelete:
+		return StatusDeleted
+	case actionResize, actionRestore:
+		return ""
+	}
+	return StatusRunning
+}
+
+// operation returns name of the action call used in tracing
+func (a Action) operation() string {
+	switch a {
+	case ActionStart:
+		return "vm.Start"
+	case ActionStop:
+		return "vm.Stop"
+	case ActionReboot:
+		return "vm.Reboot"
+	case ActionForceStop:
+		return "vm.ForceStop"
+	}
+	return "vm.PerformAction"
+}
+
+// form returns path segment and form values of the action request
+func (a Action) form(id uuid.UUID) (string, url.Values) {
+	d := url.Values{"uuid": []string{id.String()}}
+	if a == ActionForceStop {
+		d.Set("force", "true")
+		return string(ActionStop), d
+	}
+	return string(a), d
+}
+
+// Transitional reports whether VM with the status is in the middle of an operation
+func (s Status) Transitional() bool {
+	switch s {
+	case StatusCreating, StatusStarting, StatusStopping, StatusDeleting:
+		return true
+	}
+	return false
+}
+
+var (
+	// ErrInvalidState is matched by every StateError
+	ErrInvalidState = errors.New("VM is in invalid state for the action")
+	// ErrAlreadyInState is matched by StateError of VM that is already in the target state of the action
+	ErrAlreadyInState = errors.New("VM is already in target state")
+	// ErrTransitioning is matched by StateError of VM in transitional state (e.g. starting)
+	ErrTransitioning = errors.New("VM is in transitional state")
+	// ErrUnknownAction is returned by PerformAction for actions other than ActionStart, ActionStop,
+	// ActionReboot and ActionForceStop
+	ErrUnknownAction = errors.New("unknown VM action")
+)
+
+// StateError is returned without sending the request when VM status doesn't allow the action
+type StateError struct {
+	UUID   uuid.UUID
+	Action Action
+	Status Status
+}
+
+func (e *StateError) Error() string {
+	return fmt.Sprintf("can not %s VM %s in %s state", e.Action, e.UUID, e.Status)
+}
+
+func (e *StateError) Is(target error) bool {
+	switch target {
+	case ErrInvalidState:
+		return true
+	case ErrAlreadyInState:
+		return e.Action != ActionReboot && e.Action.target() == e.Status
+	case ErrTransitioning:
+		return e.Status.Transitional()
+	}
+	return false
+}
+
+// checkState returns StateError when VM status doesn't allow the action
+func checkState(vm VM, action Action) error {
+	ok := !vm.Status.Transitional()
+	switch action {
+	case ActionReboot:
+		ok = ok && vm.Status == StatusRunning
+	default:
+		ok = ok && vm.Status != action.target()
+	}
+	if !ok {
+		return &StateError{UUID: vm.UUID, Action: action, Status: vm.Status}
+	}
+	return nil
+}
+
+// Start starts stopped VM, see PerformAction
+func (c *Client) Start(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (VM, error) {
+	return c.PerformAction(ctx, id, ActionStart, opts...)
+}
+
+// Stop gracefully shuts down running VM, see PerformAction
+func (c *Client) Stop(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (VM, error) {
+	return c.PerformAction(ctx, id, ActionStop, opts...)
+}
+
+// Reboot restarts running VM, see PerformAction. It doesn't wait even with WaitForStatus set.
+func (c *Client) Reboot(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (VM, error) {
+	return c.PerformAction(ctx, id, ActionReboot, opts...)
+}
+
+// ForceStop powers off running VM without graceful shutdown, see PerformAction
+func (c *Client) ForceStop(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (VM, error) {
+	return c.PerformAction(ctx, id, ActionForceStop, opts...)
+}
+
+// PerformAction https://api.warren.io/#vm-actions
+// The VM status is checked first, *StateError is returned when the VM is already in the target state
+// of the action or in transitional state. With WaitForStatus set, it blocks until the VM reaches
+// the target state or ctx is done, except for ActionReboot: the VM is running before and after
+// the reboot, and polling may miss the restart in between.
+func (c *Client) PerformAction(ctx context.Context, id uuid.UUID, action Action, opts ...api.CallOption) (VM, error) {
+	if !action.valid() {
+		return VM{}, fmt.Errorf("%w: %q", ErrUnknownAction, action)
+	}
+	loc, err := c.location(opts)
+	if err != nil {
+		return VM{}, err
+	}
+	vm, err := c.GetVM(ctx, id, opts...)
+	if err != nil {
+		return VM{}, err
+	}
+	if err := checkState(vm, action); err != nil {
+		return vm, err
+	}
+
+	vm, err = c.action(ctx, loc, id, action)
+	if err != nil || !c.WaitForStatus || action == ActionReboot {
+		return vm, err
+	}
+	return c.awaitStatus(ctx, id, action.target(), opts)
+}
+
+// action sends the action request without checking the VM status
+func (c *Client) action(ctx context.Context, loc string, id uuid.UUID, action Action) (VM, error) {
+	segment, d := action.form(id)
+	rc := api.RequestConfig{
+		Method:     "POST",
+		Path:       fmt.Sprintf("/v1/%s/user-resource/vm/%s", loc, segment),
+		Data:       d,
+		Operation:  action.operation(),
+		Location:   loc,
+		ResourceID: id.String(),
+	}
+	return api.Do[VM](ctx, c.API, rc)
+}
+
+// Delete https://api.warren.io/#delete-vm
+// The VM must not be in transitional state. With WaitForStatus set, it blocks until the VM is gone.
+func (c *Client) Delete(ctx context.Context, id uuid.UUID, opts ...api.CallOption) error {
+	loc, err := c.location(opts)
+	if err != nil {
+		return err
+	}
+	vm, err := c.GetVM(ctx, id, opts...)
+	if err != nil {
+		return err
+	}
+	if err := checkState(vm, actionDelete); err != nil {
+		return err
+	}
+
+	rc := api.RequestConfig{
+		Method:     "DELETE",
+		Path:       fmt.Sprintf("/v1/%s/user-resource/vm", loc),
+		Query:      url.Values{"uuid": []string{id.String()}},
+		Operation:  "vm.Delete",
+		Location:   loc,
+		ResourceID: id.String(),
+	}
+	if err := api.DoNoContent(ctx, c.API, rc); err != nil || !c.WaitForStatus {
+		return err
+	}
+	_, err = c.awaitStatus(ctx, id, StatusDeleted, opts)
+	return err
+}
+
+// awaitStatus polls the VM every PollInterval until it reaches status or ctx is done
+func (c *Client) awaitStatus(ctx context.Context, id uuid.UUID, status Status, opts []api.CallOption) (VM, error) {
+	interval := c.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	return WaitForStatus(ctx, c, id, status, wait.Config{Interval: interval}, opts...)
+}
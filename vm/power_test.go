@@ -0,0 +1,159 @@
+package vm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ekaputra07/warren-go/api"
+	"github.com/ekaputra07/warren-go/wait"
+	"github.com/stretchr/testify/assert"
+)
+
+// vmServer serves VM with status returned by status on GET, other requests are passed to handle.
+func vmServer(t *testing.T, status func() Status, handle http.HandlerFunc) *api.API {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			json.NewEncoder(w).Encode(VM{UUID: id, Status: status()})
+			return
+		}
+		handle(w, r)
+	})
+	t.Cleanup(s.Close)
+	return a
+}
+
+func TestStart(t *testing.T) {
+	a := vmServer(t, func() Status { return StatusStopped }, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, fmt.Sprintf("/v1/%s/user-resource/vm/start", loc), r.RequestURI)
+		_ = r.ParseForm()
+		assert.Equal(t, id.String(), r.PostForm.Get("uuid"))
+	})
+
+	_, err := NewClient(a, loc).Start(context.Background(), id)
+	assert.NoError(t, err)
+}
+
+func TestForceStop(t *testing.T) {
+	a := vmServer(t, func() Status { return StatusRunning }, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, fmt.Sprintf("/v1/%s/user-resource/vm/stop", loc), r.RequestURI)
+		_ = r.ParseForm()
+		assert.Equal(t, "true", r.PostForm.Get("force"))
+	})
+
+	_, err := NewClient(a, loc).ForceStop(context.Background(), id)
+	assert.NoError(t, err)
+}
+
+func TestDelete(t *testing.T) {
+	a := vmServer(t, func() Status { return StatusStopped }, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method)
+		assert.Equal(t, fmt.Sprintf("/v1/%s/user-resource/vm?uuid=%s", loc, id), r.RequestURI)
+	})
+
+	assert.NoError(t, NewClient(a, loc).Delete(context.Background(), id))
+}
+
+func TestPerformAction_StateError(t *testing.T) {
+	tests := []struct {
+		status     Status
+		action     Action
+		already    bool
+		transition bool
+	}{
+		{StatusRunning, ActionStart, true, false},
+		{StatusStopped, ActionStop, true, false},
+		{StatusStopped, ActionForceStop, true, false},
+		{StatusStopped, ActionReboot, false, false},
+		{StatusStarting, ActionStop, false, true},
+		{StatusStopping, ActionStart, false, true},
+		{StatusCreating, ActionReboot, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %s", tt.action, tt.status), func(t *testing.T) {
+			a := vmServer(t, func() Status { return tt.status }, func(w http.ResponseWriter, r *http.Request) {
+				t.Error("action must not be sent")
+			})
+
+			_, err := NewClient(a, loc).PerformAction(context.Background(), id, tt.action)
+			assert.ErrorIs(t, err, ErrInvalidState)
+			assert.Equal(t, tt.already, errors.Is(err, ErrAlreadyInState))
+			assert.Equal(t, tt.transition, errors.Is(err, ErrTransitioning))
+
+			var stateErr *StateError
+			assert.ErrorAs(t, err, &stateErr)
+			assert.Equal(t, tt.status, stateErr.Status)
+		})
+	}
+}
+
+func TestStop_WaitForStatus(t *testing.T) {
+	var polls atomic.Int32
+	a := vmServer(t, func() Status {
+		switch polls.Add(1) {
+		case 1:
+			return StatusRunning
+		case 2, 3:
+			return StatusStopping
+		}
+		return StatusStopped
+	}, func(w http.ResponseWriter, r *http.Request) {})
+
+	vm, err := NewClient(a, loc).WithWaitForStatus(time.Millisecond).Stop(context.Background(), id)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusStopped, vm.Status)
+	assert.Equal(t, int32(4), polls.Load())
+}
+
+func TestReboot_WaitForStatus(t *testing.T) {
+	var polls atomic.Int32
+	a := vmServer(t, func() Status {
+		polls.Add(1)
+		return StatusRunning
+	}, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, fmt.Sprintf("/v1/%s/user-resource/vm/reboot", loc), r.RequestURI)
+	})
+
+	// running before the reboot, the status isn't polled
+	_, err := NewClient(a, loc).WithWaitForStatus(time.Millisecond).Reboot(context.Background(), id)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), polls.Load())
+}
+
+func TestPerformAction_UnknownAction(t *testing.T) {
+	a := vmServer(t, func() Status {
+		t.Error("VM must not be fetched")
+		return StatusRunning
+	}, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("action must not be sent")
+	})
+
+	for _, action := range []Action{"delete", actionResize, ""} {
+		_, err := NewClient(a, loc).PerformAction(context.Background(), id, action)
+		assert.ErrorIs(t, err, ErrUnknownAction)
+	}
+}
+
+func TestStop_WaitForStatus_Deadline(t *testing.T) {
+	var polls atomic.Int32
+	a := vmServer(t, func() Status {
+		if polls.Add(1) == 1 {
+			return StatusRunning
+		}
+		return StatusStopping
+	}, func(w http.ResponseWriter, r *http.Request) {})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	vm, err := NewClient(a, loc).WithWaitForStatus(5*time.Millisecond).Stop(ctx, id)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, err, wait.ErrTimeout)
+	assert.ErrorContains(t, err, "last seen: stopping")
+	assert.Equal(t, StatusStopping, vm.Status)
+}
@@ -0,0 +1,169 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ekaputra07/warren-go/api"
+	"github.com/google/uuid"
+)
+
+// Limits are VM resource limits of a location
+type Limits struct {
+	MinVCPU     int `json:"min_vcpu"`
+	MaxVCPU     int `json:"max_vcpu"`
+	MinMemoryMB int `json:"min_ram"`
+	MaxMemoryMB int `json:"max_ram"`
+	// OnlineResize is true when running VM can be resized, otherwise it has to be stopped first
+	OnlineResize bool `json:"online_resize"`
+}
+
+// Size is vCPU and RAM of VM
+type Size struct {
+	VCPU     int
+	MemoryMB int
+}
+
+func (s Size) String() string {
+	return fmt.Sprintf("%d vCPU / %d MB", s.VCPU, s.MemoryMB)
+}
+
+// ResizeResult reports what was changed by Resize
+type ResizeResult struct {
+	VM  VM
+	Old Size
+	New Size
+	// Restarted is true when the VM was stopped for the resize and started again
+	Restarted bool
+}
+
+// restartTimeout limits starting the VM again after failed resize, which is done even if ctx is done
+const restartTimeout = 30 * time.Second
+
+// validate returns SpecError when size is out of the limits
+func (l Limits) validate(s Size) error {
+	switch {
+	case s.VCPU < l.MinVCPU || s.VCPU > l.MaxVCPU:
+		return &SpecError{"VCPU", fmt.Sprintf("must be between %d and %d", l.MinVCPU, l.MaxVCPU)}
+	case s.MemoryMB < l.MinMemoryMB || s.MemoryMB > l.MaxMemoryMB:
+		return &SpecError{"MemoryMB", fmt.Sprintf("must be between %d and %d", l.MinMemoryMB, l.MaxMemoryMB)}
+	}
+	return nil
+}
+
+// GetLimits https://api.warren.io/#vm-limits
+func (c *Client) GetLimits(ctx context.Context, opts ...api.CallOption) (Limits, error) {
+	loc, err := c.location(opts)
+	if err != nil {
+		return Limits{}, err
+	}
+	rc := api.RequestConfig{
+		Method:    "GET",
+		Path:      fmt.Sprintf("/v1/%s/config/vm_limits", loc),
+		Operation: "vm.GetLimits",
+		Location:  loc,
+	}
+	return api.Do[Limits](ctx, c.API, rc)
+}
+
+// Resize https://api.warren.io/#change-vm-configuration
+// The new size is validated against limits of the location. When the location doesn't support
+// resizing running VM, the VM is stopped, resized and started again, waiting for each status change.
+// If waiting for the stop or the resize itself fails, the VM is started again.
+//
+// In dry-run mode the whole sequence is added to the plan and the result reports the old and new
+// size, the returned error matches api.ErrDryRun.
+func (c *Client) Resize(ctx context.Context, id uuid.UUID, vcpu, ramMB int, opts ...api.CallOption) (ResizeResult, error) {
+	loc, err := c.location(opts)
+	if err != nil {
+		return ResizeResult{}, err
+	}
+	vm, err := c.GetVM(ctx, id, opts...)
+	if err != nil {
+		return ResizeResult{}, err
+	}
+	res := ResizeResult{
+		VM:  vm,
+		Old: Size{VCPU: vm.VCPU, MemoryMB: vm.MemoryMB},
+		New: Size{VCPU: vcpu, MemoryMB: ramMB},
+	}
+	if res.Old == res.New {
+		return res, nil
+	}
+	limits, err := c.GetLimits(ctx, opts...)
+	if err != nil {
+		return res, err
+	}
+	if err := limits.validate(res.New); err != nil {
+		return res, err
+	}
+	if vm.Status.Transitional() {
+		return res, &StateError{UUID: id, Action: actionResize, Status: vm.Status}
+	}
+
+	// planned calls don't change the VM, so there's nothing to wait for
+	dryRun := func(err error) bool { return errors.Is(err, api.ErrDryRun) }
+	var planned error
+
+	// startAgain starts the VM stopped for the resize after a failed step, best-effort
+	startAgain := func(err error, step string) error {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), restartTimeout)
+		defer cancel()
+		if _, startErr := c.action(ctx, loc, id, ActionStart); startErr != nil {
+			return errors.Join(err, fmt.Errorf("starting VM after failed %s: %w", step, startErr))
+		}
+		res.Restarted = true
+		return err
+	}
+
+	restart := vm.Status == StatusRunning && !limits.OnlineResize
+	if restart {
+		if _, err := c.action(ctx, loc, id, ActionStop); dryRun(err) {
+			planned = err
+		} else if err != nil {
+			return res, err
+		} else if _, err := c.awaitStatus(ctx, id, StatusStopped, opts); err != nil {
+			return res, startAgain(err, "stop")
+		}
+	}
+
+	rc := api.RequestConfig{
+		Method: "POST",
+		Path:   fmt.Sprintf("/v1/%s/user-resource/vm/resize", loc),
+		Data: url.Values{
+			"uuid": []string{id.String()},
+			"vcpu": []string{strconv.Itoa(vcpu)},
+			"ram":  []string{strconv.Itoa(ramMB)},
+		},
+		Operation:  "vm.Resize",
+		Location:   loc,
+		ResourceID: id.String(),
+	}
+	resized, err := api.Do[VM](ctx, c.API, rc)
+	switch {
+	case dryRun(err):
+		planned = err
+	case err != nil && restart:
+		return res, startAgain(err, "resize")
+	case err != nil:
+		return res, err
+	default:
+		res.VM = resized
+	}
+
+	if !restart {
+		return res, planned
+	}
+	res.Restarted = true
+	if _, err := c.action(ctx, loc, id, ActionStart); err != nil {
+		return res, err
+	}
+	if res.VM, err = c.awaitStatus(ctx, id, StatusRunning, opts); err != nil {
+		return res, err
+	}
+	return res, planned
+}
@@ -0,0 +1,168 @@
+package vm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ekaputra07/warren-go/api"
+	"github.com/stretchr/testify/assert"
+)
+
+var limits = Limits{MinVCPU: 1, MaxVCPU: 8, MinMemoryMB: 512, MaxMemoryMB: 16384}
+
+// resizeServer emulates a running VM with 2 vCPU and 4096 MB RAM, failing the resize
+// with given status code if not zero. It returns the API and the non-GET requests sent.
+func resizeServer(t *testing.T, l Limits, failResize int) (*api.API, func() []string) {
+	var mu sync.Mutex
+	vm := VM{UUID: id, Status: StatusRunning, VCPU: 2, MemoryMB: 4096}
+	var sent []string
+
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == fmt.Sprintf("/v1/%s/config/vm_limits", loc) {
+			json.NewEncoder(w).Encode(l)
+			return
+		}
+		if r.Method != "GET" {
+			_ = r.ParseForm()
+			sent = append(sent, r.URL.Path[len("/v1/jkt01/user-resource/vm/"):])
+		}
+		switch r.URL.Path[len("/v1/jkt01/user-resource/vm"):] {
+		case "/stop":
+			vm.Status = StatusStopped
+		case "/start":
+			vm.Status = StatusRunning
+		case "/resize":
+			if failResize != 0 {
+				w.WriteHeader(failResize)
+				return
+			}
+			vm.VCPU = atoi(r.PostForm.Get("vcpu"))
+			vm.MemoryMB = atoi(r.PostForm.Get("ram"))
+		}
+		json.NewEncoder(w).Encode(vm)
+	})
+	t.Cleanup(s.Close)
+	return a, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return sent
+	}
+}
+
+func atoi(s string) int {
+	var i int
+	fmt.Sscan(s, &i)
+	return i
+}
+
+func TestResize_Online(t *testing.T) {
+	l := limits
+	l.OnlineResize = true
+	a, sent := resizeServer(t, l, 0)
+
+	res, err := NewClient(a, loc).Resize(context.Background(), id, 4, 8192)
+	assert.NoError(t, err)
+	assert.Equal(t, Size{VCPU: 2, MemoryMB: 4096}, res.Old)
+	assert.Equal(t, Size{VCPU: 4, MemoryMB: 8192}, res.New)
+	assert.False(t, res.Restarted)
+	assert.Equal(t, 4, res.VM.VCPU)
+	assert.Equal(t, []string{"resize"}, sent())
+}
+
+func TestResize_Restart(t *testing.T) {
+	a, sent := resizeServer(t, limits, 0)
+
+	res, err := NewClient(a, loc).Resize(context.Background(), id, 4, 8192)
+	assert.NoError(t, err)
+	assert.True(t, res.Restarted)
+	assert.Equal(t, StatusRunning, res.VM.Status)
+	assert.Equal(t, 8192, res.VM.MemoryMB)
+	assert.Equal(t, []string{"stop", "resize", "start"}, sent())
+}
+
+func TestResize_FailedStartsAgain(t *testing.T) {
+	a, sent := resizeServer(t, limits, http.StatusInternalServerError)
+
+	res, err := NewClient(a, loc).Resize(context.Background(), id, 4, 8192)
+	assert.True(t, api.HasStatus(err, http.StatusInternalServerError))
+	assert.True(t, res.Restarted)
+	assert.Equal(t, []string{"stop", "resize", "start"}, sent())
+}
+
+func TestResize_StopTimeoutStartsAgain(t *testing.T) {
+	var mu sync.Mutex
+	var sent []string
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == fmt.Sprintf("/v1/%s/config/vm_limits", loc) {
+			json.NewEncoder(w).Encode(limits)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		status := StatusRunning
+		if r.Method != "GET" {
+			sent = append(sent, r.URL.Path[len("/v1/jkt01/user-resource/vm/"):])
+		} else if len(sent) > 0 {
+			// never stops
+			status = StatusStopping
+		}
+		json.NewEncoder(w).Encode(VM{UUID: id, Status: status, VCPU: 2, MemoryMB: 4096})
+	})
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	res, err := NewClient(a, loc).WithWaitForStatus(5*time.Millisecond).Resize(ctx, id, 4, 8192)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, res.Restarted)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"stop", "start"}, sent)
+}
+
+func TestResize_OutOfLimits(t *testing.T) {
+	a, sent := resizeServer(t, limits, 0)
+
+	_, err := NewClient(a, loc).Resize(context.Background(), id, 16, 8192)
+	assert.ErrorIs(t, err, ErrInvalidSpec)
+	assert.ErrorContains(t, err, "VCPU must be between 1 and 8")
+
+	_, err = NewClient(a, loc).Resize(context.Background(), id, 4, 256)
+	assert.ErrorIs(t, err, ErrInvalidSpec)
+	assert.Empty(t, sent())
+}
+
+func TestResize_Unchanged(t *testing.T) {
+	a, sent := resizeServer(t, limits, 0)
+
+	res, err := NewClient(a, loc).Resize(context.Background(), id, 2, 4096)
+	assert.NoError(t, err)
+	assert.Equal(t, res.Old, res.New)
+	assert.Empty(t, sent())
+}
+
+func TestResize_DryRun(t *testing.T) {
+	a, sent := resizeServer(t, limits, 0)
+	plan := &api.Plan{}
+	a.DryRun = plan
+
+	res, err := NewClient(a, loc).Resize(context.Background(), id, 4, 8192)
+	assert.ErrorIs(t, err, api.ErrDryRun)
+	assert.Equal(t, Size{VCPU: 2, MemoryMB: 4096}, res.Old)
+	assert.Equal(t, Size{VCPU: 4, MemoryMB: 8192}, res.New)
+	assert.True(t, res.Restarted)
+	assert.Empty(t, sent())
+
+	ops := []string{}
+	for _, c := range plan.Calls() {
+		ops = append(ops, c.Operation)
+	}
+	assert.Equal(t, []string{"vm.Stop", "vm.Resize", "vm.Start"}, ops)
+}
@@ -0,0 +1,147 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidSpec is matched (using errors.Is) by SpecError
+var ErrInvalidSpec = errors.New("invalid VM spec")
+
+// SpecError is returned without sending the request when CreateVMSpec is invalid
+type SpecError struct {
+	Field  string
+	Reason string
+}
+
+func (e *SpecError) Error() string {
+	return fmt.Sprintf("%s: %s %s", ErrInvalidSpec, e.Field, e.Reason)
+}
+
+func (e *SpecError) Is(target error) bool {
+	return target == ErrInvalidSpec
+}
+
+// Minimum resources of a VM
+const (
+	MinMemoryMB   = 512
+	MinDiskSizeGB = 20
+)
+
+// name of VM is also its hostname
+var nameRe = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+
+// CreateVMSpec is specification of a new VM.
+// The VM boots either from OS image (OSName and OSVersion) or from copy of SourceSnapshot, SourceDisk
+// or SourceBackup.
+type CreateVMSpec struct {
+	Name      string
+	OSName    string
+	OSVersion string
+	VCPU      int
+	MemoryMB  int
+	// DiskSizeGB is size of the primary disk
+	DiskSizeGB int
+	// NetworkUUID is the VPC network to join, the default network of the location if not set
+	NetworkUUID uuid.UUID
+	// BillingAccountID is the client billing account if not set, or the default billing account if neither is set
+	BillingAccountID int
+
+	// Username of the initial user, authenticated with Password and/or PublicKey (at least one is required)
+	Username  string
+	Password  string
+	PublicKey string
+	// CloudInit is cloud-init user data
+	CloudInit string
+
+	// ReservePublicIP creates a floating IP assigned to the VM
+	ReservePublicIP bool
+
+	SourceSnapshot uuid.UUID
+	SourceDisk     uuid.UUID
+	// SourceBackup is a backup point of another VM, see ListBackups
+	SourceBackup uuid.UUID
+}
+
+// Validate checks the spec without calling the API, returned error is *SpecError
+func (s CreateVMSpec) Validate() error {
+	var sources []string
+	for _, src := range []struct {
+		field string
+		id    uuid.UUID
+	}{{"SourceSnapshot", s.SourceSnapshot}, {"SourceDisk", s.SourceDisk}, {"SourceBackup", s.SourceBackup}} {
+		if src.id != uuid.Nil {
+			sources = append(sources, src.field)
+		}
+	}
+	hasSource := len(sources) > 0
+	switch {
+	case !nameRe.MatchString(s.Name):
+		return &SpecError{"Name", "must be a valid hostname"}
+	case !hasSource && s.OSName == "":
+		return &SpecError{"OSName", "is required"}
+	case !hasSource && s.OSVersion == "":
+		return &SpecError{"OSVersion", "is required"}
+	case len(sources) > 1:
+		return &SpecError{sources[0], "can not be combined with " + strings.Join(sources[1:], " and ")}
+	case s.VCPU < 1:
+		return &SpecError{"VCPU", "must be at least 1"}
+	case s.MemoryMB < MinMemoryMB:
+		return &SpecError{"MemoryMB", fmt.Sprintf("must be at least %d", MinMemoryMB)}
+	case s.DiskSizeGB < MinDiskSizeGB:
+		return &SpecError{"DiskSizeGB", fmt.Sprintf("must be at least %d", MinDiskSizeGB)}
+	case s.BillingAccountID < 0:
+		return &SpecError{"BillingAccountID", "must not be negative"}
+	case s.Username == "":
+		return &SpecError{"Username", "is required"}
+	case s.Password == "" && s.PublicKey == "":
+		return &SpecError{"Password", "or PublicKey is required"}
+	case s.PublicKey != "" && len(strings.Fields(s.PublicKey)) < 2:
+		return &SpecError{"PublicKey", "must be in OpenSSH authorized_keys format"}
+	}
+	return nil
+}
+
+// values returns the spec as form values of create VM request
+func (s CreateVMSpec) values() url.Values {
+	d := url.Values{
+		"name":              []string{s.Name},
+		"vcpu":              []string{strconv.Itoa(s.VCPU)},
+		"ram":               []string{strconv.Itoa(s.MemoryMB)},
+		"disks":             []string{strconv.Itoa(s.DiskSizeGB)},
+		"username":          []string{s.Username},
+		"reserve_public_ip": []string{strconv.FormatBool(s.ReservePublicIP)},
+	}
+	set := func(k, v string) {
+		if v != "" {
+			d.Set(k, v)
+		}
+	}
+	set("os_name", s.OSName)
+	set("os_version", s.OSVersion)
+	set("password", s.Password)
+	set("public_key", s.PublicKey)
+	set("cloud_init", s.CloudInit)
+	if s.NetworkUUID != uuid.Nil {
+		d.Set("network_uuid", s.NetworkUUID.String())
+	}
+	if s.BillingAccountID != 0 {
+		d.Set("billing_account_id", strconv.Itoa(s.BillingAccountID))
+	}
+	if s.SourceSnapshot != uuid.Nil {
+		d.Set("source_replica", s.SourceSnapshot.String())
+	}
+	if s.SourceDisk != uuid.Nil {
+		d.Set("source_uuid", s.SourceDisk.String())
+	}
+	if s.SourceBackup != uuid.Nil {
+		d.Set("source_backup", s.SourceBackup.String())
+	}
+	return d
+}
@@ -0,0 +1,100 @@
+package vm
+
+import (
+	"context"
+	"time"
+
+	"github.com/ekaputra07/warren-go/api"
+	"github.com/google/uuid"
+)
+
+// Service is implemented by Client, use it in place of *Client to be able to mock the API.
+// Every method accepts call options to override scope of the client for that single call,
+// options that don't apply to the method are rejected with api.ErrUnsupportedOption.
+type Service interface {
+	ListVMs(ctx context.Context, opts ...api.CallOption) ([]VM, error)
+	GetVM(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (VM, error)
+	CreateVM(ctx context.Context, spec CreateVMSpec, opts ...api.CallOption) (VM, error)
+	Start(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (VM, error)
+	Stop(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (VM, error)
+	Reboot(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (VM, error)
+	ForceStop(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (VM, error)
+	PerformAction(ctx context.Context, id uuid.UUID, action Action, opts ...api.CallOption) (VM, error)
+	Delete(ctx context.Context, id uuid.UUID, opts ...api.CallOption) error
+	GetLimits(ctx context.Context, opts ...api.CallOption) (Limits, error)
+	Resize(ctx context.Context, id uuid.UUID, vcpu, ramMB int, opts ...api.CallOption) (ResizeResult, error)
+	EnableBackups(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (VM, error)
+	DisableBackups(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (VM, error)
+	ListBackups(ctx context.Context, id uuid.UUID, opts ...api.CallOption) ([]Backup, error)
+	RestoreBackup(ctx context.Context, id, backupID uuid.UUID, opts ...api.CallOption) (VM, error)
+}
+
+var _ Service = (*Client)(nil)
+
+// Client is safe for concurrent use, use WithLocation/WithBillingAccount or api.InLocation/api.ForBillingAccount
+// call options instead of modifying Location or BillingAccountID of a shared client.
+// Billing account is used by CreateVM only.
+type Client struct {
+	API              *api.API
+	Location         string
+	BillingAccountID int
+
+	// WaitForStatus makes power actions other than reboot and Delete block until the VM reaches the target status
+	WaitForStatus bool
+	// PollInterval is how often the VM is polled while waiting, DefaultPollInterval if zero
+	PollInterval time.Duration
+}
+
+// Status of virtual machine
+type Status string
+
+const (
+	StatusCreating Status = "creating"
+	StatusStarting Status = "starting"
+	StatusRunning  Status = "running"
+	StatusStopping Status = "stopping"
+	StatusStopped  Status = "stopped"
+	StatusDeleting Status = "deleting"
+	StatusDeleted  Status = "deleted"
+)
+
+// Storage is a disk attached to virtual machine
+type Storage struct {
+	ID        int       `json:"id"`
+	UUID      uuid.UUID `json:"uuid"`
+	Name      string    `json:"name"`
+	Pool      string    `json:"pool"`
+	Type      string    `json:"type"`
+	Primary   bool      `json:"primary"`
+	Shared    bool      `json:"shared"`
+	SizeGB    int       `json:"size"`
+	UserID    int       `json:"user_id"`
+	CreatedAt string    `json:"created_at"`
+	UpdatedAt string    `json:"updated_at"`
+}
+
+// VM represents virtual machine
+type VM struct {
+	ID               int       `json:"id"`
+	UUID             uuid.UUID `json:"uuid"`
+	Name             string    `json:"name"`
+	Hostname         string    `json:"hostname"`
+	Description      string    `json:"description"`
+	Status           Status    `json:"status"`
+	VCPU             int       `json:"vcpu"`
+	MemoryMB         int       `json:"memory"`
+	OSName           string    `json:"os_name"`
+	OSVersion        string    `json:"os_version"`
+	Storage          []Storage `json:"storage"`
+	MAC              string    `json:"mac"`
+	PrivateIPv4      string    `json:"private_ipv4"`
+	PublicIPv4       string    `json:"public_ipv4"`
+	PublicIPv6       string    `json:"public_ipv6"`
+	NetworkUUID      uuid.UUID `json:"network_uuid"`
+	Backup           bool      `json:"backup"`
+	BillingAccountID int       `json:"billing_account"`
+	UserID           int       `json:"user_id"`
+	Username         string    `json:"username"`
+	CreatedAt        string    `json:"created_at"`
+	UpdatedAt        string    `json:"updated_at"`
+}
@@ -1 +1,109 @@
 package vm
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/ekaputra07/warren-go/api"
+	"github.com/google/uuid"
+)
+
+func NewClient(client *api.API, location string) *Client {
+	return &Client{
+		API:      client,
+		Location: location,
+	}
+}
+
+// WithLocation returns copy of the client scoped to given data center location
+func (c *Client) WithLocation(location string) *Client {
+	cc := *c
+	cc.Location = location
+	return &cc
+}
+
+// WithBillingAccount returns copy of the client scoped to given billing account
+func (c *Client) WithBillingAccount(id int) *Client {
+	cc := *c
+	cc.BillingAccountID = id
+	return &cc
+}
+
+// WithWaitForStatus returns copy of the client that waits for power actions (except reboot) to complete,
+// polling the VM every interval (DefaultPollInterval if zero)
+func (c *Client) WithWaitForStatus(interval time.Duration) *Client {
+	cc := *c
+	cc.WaitForStatus = true
+	cc.PollInterval = interval
+	return &cc
+}
+
+// scope returns scope of the call, which is the client scope unless overridden by options.
+// Options overriding fields other than supported are rejected.
+func (c *Client) scope(opts []api.CallOption, supported api.ScopeField) (api.Scope, error) {
+	return api.Scope{Location: c.Location, BillingAccountID: c.BillingAccountID}.Resolve(opts, supported)
+}
+
+// location returns data center location of the call for methods that don't use billing account
+func (c *Client) location(opts []api.CallOption) (string, error) {
+	s, err := c.scope(opts, api.ScopeLocation)
+	return s.Location, err
+}
+
+// ListVMs https://api.warren.io/#list-vms
+func (c *Client) ListVMs(ctx context.Context, opts ...api.CallOption) ([]VM, error) {
+	loc, err := c.location(opts)
+	if err != nil {
+		return nil, err
+	}
+	rc := api.RequestConfig{
+		Method:    "GET",
+		Path:      fmt.Sprintf("/v1/%s/user-resource/vm/list", loc),
+		Operation: "vm.ListVMs",
+		Location:  loc,
+	}
+	return api.Do[[]VM](ctx, c.API, rc)
+}
+
+// GetVM https://api.warren.io/#get-vm
+func (c *Client) GetVM(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (VM, error) {
+	loc, err := c.location(opts)
+	if err != nil {
+		return VM{}, err
+	}
+	rc := api.RequestConfig{
+		Method:     "GET",
+		Path:       fmt.Sprintf("/v1/%s/user-resource/vm", loc),
+		Query:      url.Values{"uuid": []string{id.String()}},
+		Operation:  "vm.GetVM",
+		Location:   loc,
+		ResourceID: id.String(),
+	}
+	return api.Do[VM](ctx, c.API, rc)
+}
+
+// CreateVM https://api.warren.io/#create-vm
+// The spec is validated before sending the request, see CreateVMSpec.Validate.
+// The VM is billed to the client billing account unless spec.BillingAccountID is set.
+func (c *Client) CreateVM(ctx context.Context, spec CreateVMSpec, opts ...api.CallOption) (VM, error) {
+	s, err := c.scope(opts, api.ScopeLocation|api.ScopeBillingAccount)
+	if err != nil {
+		return VM{}, err
+	}
+	if spec.BillingAccountID == 0 {
+		spec.BillingAccountID = s.BillingAccountID
+	}
+	if err := spec.Validate(); err != nil {
+		return VM{}, err
+	}
+	rc := api.RequestConfig{
+		Method:    "POST",
+		Path:      fmt.Sprintf("/v1/%s/user-resource/vm", s.Location),
+		Data:      spec.values(),
+		Operation: "vm.CreateVM",
+		Location:  s.Location,
+	}
+	return api.Do[VM](ctx, c.API, rc)
+}
@@ -0,0 +1,208 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/ekaputra07/warren-go/api"
+	"github.com/ekaputra07/warren-go/internal/scopetest"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	loc string    = "jkt01"
+	id  uuid.UUID = uuid.MustParse("7b9e4c2a-1d3f-4e5a-8b6c-9d0e1f2a3b4c")
+)
+
+func TestListVMs(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, fmt.Sprintf("/v1/%s/user-resource/vm/list", loc), r.RequestURI)
+	})
+	defer s.Close()
+
+	vm := Client{API: a, Location: loc}
+	vm.ListVMs(context.Background())
+}
+
+func TestGetVM(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, fmt.Sprintf("/v1/%s/user-resource/vm?uuid=%s", loc, id), r.RequestURI)
+	})
+	defer s.Close()
+
+	vm := Client{API: a, Location: loc}
+	vm.GetVM(context.Background(), id)
+}
+
+func TestGetVM_InLocation(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, fmt.Sprintf("/v1/sgp01/user-resource/vm?uuid=%s", id), r.RequestURI)
+	})
+	defer s.Close()
+
+	vm := NewClient(a, loc)
+	vm.GetVM(context.Background(), id, api.InLocation("sgp01"))
+}
+
+func TestMissingLocation(t *testing.T) {
+	vm := NewClient(api.Default, "")
+
+	_, err := vm.ListVMs(context.Background())
+	assert.ErrorIs(t, err, api.ErrMissingLocation)
+
+	_, err = vm.GetVM(context.Background(), id)
+	assert.ErrorIs(t, err, api.ErrMissingLocation)
+}
+
+func TestGetVM_ForBillingAccount(t *testing.T) {
+	vm := NewClient(api.Default, loc)
+	_, err := vm.GetVM(context.Background(), id, api.ForBillingAccount(1))
+	assert.ErrorIs(t, err, api.ErrUnsupportedOption)
+}
+
+// run with -race to verify a shared client is safe to use with different scopes
+func TestClient_Concurrent(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(fmt.Sprintf(`{"name": %q}`, r.URL.Path)))
+	})
+	defer s.Close()
+	c := NewClient(a, loc)
+
+	check := func(s api.Scope, vm VM, err error) {
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("/v1/%s/user-resource/vm", s.Location), vm.Name)
+	}
+	scopetest.Concurrent(
+		func(s api.Scope) {
+			vm, err := c.WithLocation(s.Location).GetVM(context.Background(), id)
+			check(s, vm, err)
+		},
+		func(s api.Scope) {
+			vm, err := c.GetVM(context.Background(), id, api.InLocation(s.Location))
+			check(s, vm, err)
+		},
+	)
+	assert.Equal(t, loc, c.Location)
+}
+
+var spec = CreateVMSpec{
+	Name:            "web-1",
+	OSName:          "ubuntu",
+	OSVersion:       "22.04",
+	VCPU:            2,
+	MemoryMB:        4096,
+	DiskSizeGB:      40,
+	NetworkUUID:     uuid.MustParse("5e2f8c1a-7d4b-4a9e-b3c6-1f0d9e8a7b52"),
+	Username:        "admin",
+	PublicKey:       "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIGq admin@example.com",
+	CloudInit:       "#cloud-config\npackages: [nginx]",
+	ReservePublicIP: true,
+}
+
+func TestCreateVM(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, fmt.Sprintf("/v1/%s/user-resource/vm", loc), r.RequestURI)
+
+		_ = r.ParseForm()
+		assert.Equal(t, url.Values{
+			"name":              []string{"web-1"},
+			"os_name":           []string{"ubuntu"},
+			"os_version":        []string{"22.04"},
+			"vcpu":              []string{"2"},
+			"ram":               []string{"4096"},
+			"disks":             []string{"40"},
+			"network_uuid":      []string{"5e2f8c1a-7d4b-4a9e-b3c6-1f0d9e8a7b52"},
+			"username":          []string{"admin"},
+			"public_key":        []string{spec.PublicKey},
+			"cloud_init":        []string{spec.CloudInit},
+			"reserve_public_ip": []string{"true"},
+		}, r.PostForm)
+	})
+	defer s.Close()
+
+	vm := Client{API: a, Location: loc}
+	vm.CreateVM(context.Background(), spec)
+}
+
+func TestCreateVM_BillingAccount(t *testing.T) {
+	var billed []string
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		billed = append(billed, r.PostForm.Get("billing_account_id"))
+	})
+	defer s.Close()
+
+	vm := NewClient(a, loc).WithBillingAccount(123)
+	_, err := vm.CreateVM(context.Background(), spec)
+	assert.NoError(t, err)
+	_, err = vm.CreateVM(context.Background(), spec, api.ForBillingAccount(456))
+	assert.NoError(t, err)
+	own := spec
+	own.BillingAccountID = 789
+	_, err = vm.CreateVM(context.Background(), own)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"123", "456", "789"}, billed)
+}
+
+func TestCreateVM_Invalid(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request must not be sent")
+	})
+	defer s.Close()
+
+	invalid := spec
+	invalid.VCPU = 0
+	_, err := NewClient(a, loc).CreateVM(context.Background(), invalid)
+	assert.ErrorIs(t, err, ErrInvalidSpec)
+
+	var specErr *SpecError
+	assert.ErrorAs(t, err, &specErr)
+	assert.Equal(t, "VCPU", specErr.Field)
+}
+
+func TestCreateVMSpec_Validate(t *testing.T) {
+	source := uuid.MustParse("3c4d5e6f-7a8b-4c9d-8e0f-1a2b3c4d5e6f")
+	tests := map[string]struct {
+		modify func(s *CreateVMSpec)
+		field  string
+	}{
+		"valid":               {func(s *CreateVMSpec) {}, ""},
+		"password only":       {func(s *CreateVMSpec) { s.PublicKey, s.Password = "", "s3cret!" }, ""},
+		"from snapshot":       {func(s *CreateVMSpec) { s.OSName, s.OSVersion, s.SourceSnapshot = "", "", source }, ""},
+		"empty name":          {func(s *CreateVMSpec) { s.Name = "" }, "Name"},
+		"invalid name":        {func(s *CreateVMSpec) { s.Name = "web_1" }, "Name"},
+		"missing os":          {func(s *CreateVMSpec) { s.OSName = "" }, "OSName"},
+		"missing os version":  {func(s *CreateVMSpec) { s.OSVersion = "" }, "OSVersion"},
+		"both sources":        {func(s *CreateVMSpec) { s.SourceSnapshot, s.SourceDisk = source, source }, "SourceSnapshot"},
+		"from backup":         {func(s *CreateVMSpec) { s.OSName, s.OSVersion, s.SourceBackup = "", "", source }, ""},
+		"disk and backup":     {func(s *CreateVMSpec) { s.SourceDisk, s.SourceBackup = source, source }, "SourceDisk"},
+		"no vcpu":             {func(s *CreateVMSpec) { s.VCPU = 0 }, "VCPU"},
+		"too little memory":   {func(s *CreateVMSpec) { s.MemoryMB = 256 }, "MemoryMB"},
+		"too small disk":      {func(s *CreateVMSpec) { s.DiskSizeGB = 10 }, "DiskSizeGB"},
+		"negative billing":    {func(s *CreateVMSpec) { s.BillingAccountID = -1 }, "BillingAccountID"},
+		"no username":         {func(s *CreateVMSpec) { s.Username = "" }, "Username"},
+		"no credentials":      {func(s *CreateVMSpec) { s.PublicKey = "" }, "Password"},
+		"malformed publickey": {func(s *CreateVMSpec) { s.PublicKey = "AAAAC3NzaC1lZDI1NTE5" }, "PublicKey"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := spec
+			tt.modify(&s)
+			err := s.Validate()
+			if tt.field == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var specErr *SpecError
+			assert.ErrorAs(t, err, &specErr)
+			assert.Equal(t, tt.field, specErr.Field)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ekaputra07/warren-go/api"
+	"github.com/ekaputra07/warren-go/wait"
+	"github.com/google/uuid"
+)
+
+// WaitForStatus polls the VM until it has given status, see wait.Until.
+// Waiting for StatusDeleted also succeeds when the VM is gone.
+func WaitForStatus(ctx context.Context, s Service, id uuid.UUID, status Status, cfg wait.Config, opts ...api.CallOption) (VM, error) {
+	return wait.Until(ctx, cfg, wait.Condition[VM]{
+		Description: fmt.Sprintf("VM %s to be %s", id, status),
+		Poll: func(ctx context.Context) (VM, error) {
+			vm, err := s.GetVM(ctx, id, opts...)
+			if status == StatusDeleted && api.IsNotFound(err) {
+				return VM{UUID: id, Status: StatusDeleted}, nil
+			}
+			return vm, err
+		},
+		Done:  func(vm VM) bool { return vm.Status == status },
+		State: func(vm VM) string { return string(vm.Status) },
+	})
+}
@@ -0,0 +1,41 @@
+package vm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ekaputra07/warren-go/api"
+	"github.com/ekaputra07/warren-go/wait"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitForStatus_Deleted(t *testing.T) {
+	var polls atomic.Int32
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		if polls.Add(1) < 3 {
+			json.NewEncoder(w).Encode(VM{UUID: id, Status: StatusDeleting})
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+	defer s.Close()
+
+	vm, err := WaitForStatus(context.Background(), NewClient(a, loc), id, StatusDeleted, wait.Config{Interval: time.Millisecond})
+	assert.NoError(t, err)
+	assert.Equal(t, StatusDeleted, vm.Status)
+	assert.Equal(t, int32(3), polls.Load())
+}
+
+func TestWaitForStatus_NotFound(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	defer s.Close()
+
+	_, err := WaitForStatus(context.Background(), NewClient(a, loc), id, StatusRunning, wait.Config{Interval: time.Millisecond})
+	assert.True(t, api.IsNotFound(err))
+}
@@ -0,0 +1,63 @@
+package vpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ekaputra07/warren-go/internal/golden"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var goldenNetwork = NetworkInfo{
+	VLANID:        1042,
+	UUID:          uuid.MustParse("5e2f8c1a-7d4b-4a9e-b3c6-1f0d9e8a7b52"),
+	Name:          "Default",
+	Subnet:        "10.42.0.0/24",
+	SubnetIPV6:    "fd00:412::/64",
+	Type:          "private",
+	IsDefault:     true,
+	ResourceCount: 2,
+	VMUUIDs: uuid.UUIDs{
+		uuid.MustParse("a1b2c3d4-e5f6-4a7b-8c9d-0e1f2a3b4c5d"),
+		uuid.MustParse("d5c4b3a2-f6e5-4b7a-9d8c-5d4c3b2a1f0e"),
+	},
+	CreatedAt: "2023-01-15 09:30:00",
+	UpdatedAt: "2023-03-20 14:45:10",
+}
+
+func TestContract_GetNetwork(t *testing.T) {
+	a, fixture := golden.Server(t, "network.json")
+
+	n, err := NewClient(a, loc).GetNetwork(context.Background(), goldenNetwork.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, goldenNetwork, n)
+	golden.AssertRoundTrip(t, fixture, n)
+}
+
+func TestContract_GetOrCreateDefaultNetwork(t *testing.T) {
+	a, _ := golden.Server(t, "network.json")
+
+	n, err := NewClient(a, loc).GetOrCreateDefaultNetwork(context.Background(), "Default")
+	assert.NoError(t, err)
+	assert.Equal(t, goldenNetwork, n)
+}
+
+func TestContract_ListNetworks(t *testing.T) {
+	a, fixture := golden.Server(t, "networks.json")
+
+	nets, err := NewClient(a, loc).ListNetworks(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []NetworkInfo{goldenNetwork, {
+		VLANID:     1043,
+		UUID:       uuid.MustParse("9c8b7a6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d"),
+		Name:       "Backend",
+		Subnet:     "10.43.0.0/24",
+		SubnetIPV6: "fd00:413::/64",
+		Type:       "private",
+		VMUUIDs:    uuid.UUIDs{},
+		CreatedAt:  "2023-01-15 09:30:00",
+		UpdatedAt:  "2023-03-20 14:45:10",
+	}}, nets)
+	golden.AssertRoundTrip(t, fixture, nets)
+}
@@ -1,10 +1,29 @@
 package vpc
 
 import (
+	"context"
+
 	"github.com/ekaputra07/warren-go/api"
 	"github.com/google/uuid"
 )
 
+// Service is implemented by Client, use it in place of *Client to be able to mock the API.
+// Every method accepts call options to override scope of the client for that single call,
+// options that don't apply to the method are rejected with api.ErrUnsupportedOption.
+type Service interface {
+	ListNetworks(ctx context.Context, opts ...api.CallOption) ([]NetworkInfo, error)
+	GetNetwork(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (NetworkInfo, error)
+	DeleteNetwork(ctx context.Context, id uuid.UUID, opts ...api.CallOption) error
+	RenameNetwork(ctx context.Context, id uuid.UUID, newName string, opts ...api.CallOption) error
+	GetOrCreateDefaultNetwork(ctx context.Context, name string, opts ...api.CallOption) (NetworkInfo, error)
+	EnsureDefaultNetwork(ctx context.Context, name string, opts ...api.CallOption) (NetworkInfo, bool, error)
+	SetDefaultNetwork(ctx context.Context, id uuid.UUID, opts ...api.CallOption) error
+}
+
+var _ Service = (*Client)(nil)
+
+// Client is safe for concurrent use, use WithLocation or api.InLocation call option
+// instead of modifying Location of a shared client.
 type Client struct {
 	API      *api.API
 	Location string
@@ -2,7 +2,6 @@ package vpc
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/url"
 
@@ -17,82 +16,154 @@ func NewClient(client *api.API, location string) *Client {
 	}
 }
 
+// WithLocation returns copy of the client scoped to given data center location
+func (c *Client) WithLocation(location string) *Client {
+	cc := *c
+	cc.Location = location
+	return &cc
+}
+
+// location returns data center location of the call, which is the client location unless overridden.
+// Networks aren't billed, so billing account can't be overridden.
+func (c *Client) location(opts []api.CallOption) (string, error) {
+	s, err := api.Scope{Location: c.Location}.Resolve(opts, api.ScopeLocation)
+	return s.Location, err
+}
+
 // ListNetworks https://api.warren.io/#list-networks
-func (c *Client) ListNetworks(ctx context.Context) ([]NetworkInfo, error) {
-	rc := api.RequestConfig{
-		Method: "GET",
-		Path:   fmt.Sprintf("/v1/%s/network/networks", c.Location),
-	}
-	res := c.API.JSONRequest(ctx, rc)
-	if res.Error != nil {
-		return nil, res.Error
-	}
-	var ni []NetworkInfo
-	if err := json.Unmarshal(res.Body, &ni); err != nil {
+func (c *Client) ListNetworks(ctx context.Context, opts ...api.CallOption) ([]NetworkInfo, error) {
+	loc, err := c.location(opts)
+	if err != nil {
 		return nil, err
 	}
-	return ni, nil
+	rc := api.RequestConfig{
+		Method:    "GET",
+		Path:      fmt.Sprintf("/v1/%s/network/networks", loc),
+		Operation: "vpc.ListNetworks",
+		Location:  loc,
+	}
+	return api.Do[[]NetworkInfo](ctx, c.API, rc)
 }
 
 // GetNetwork https://api.warren.io/#get-network-data
-func (c *Client) GetNetwork(ctx context.Context, id uuid.UUID) (NetworkInfo, error) {
-	var ni NetworkInfo
-	rc := api.RequestConfig{
-		Method: "GET",
-		Path:   fmt.Sprintf("/v1/%s/network/network/%s", c.Location, id),
-	}
-	res := c.API.JSONRequest(ctx, rc)
-	if res.Error != nil {
-		return ni, res.Error
+func (c *Client) GetNetwork(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (NetworkInfo, error) {
+	loc, err := c.location(opts)
+	if err != nil {
+		return NetworkInfo{}, err
 	}
-	if err := json.Unmarshal(res.Body, &ni); err != nil {
-		return ni, err
+	rc := api.RequestConfig{
+		Method:     "GET",
+		Path:       fmt.Sprintf("/v1/%s/network/network/%s", loc, id),
+		Operation:  "vpc.GetNetwork",
+		Location:   loc,
+		ResourceID: id.String(),
 	}
-	return ni, nil
+	return api.Do[NetworkInfo](ctx, c.API, rc)
 }
 
 // DeleteNetwork https://api.warren.io/#delete-network
-func (c *Client) DeleteNetwork(ctx context.Context, id uuid.UUID) error {
+func (c *Client) DeleteNetwork(ctx context.Context, id uuid.UUID, opts ...api.CallOption) error {
+	loc, err := c.location(opts)
+	if err != nil {
+		return err
+	}
 	rc := api.RequestConfig{
-		Method: "DELETE",
-		Path:   fmt.Sprintf("/v1/%s/network/network/%s", c.Location, id),
+		Method:     "DELETE",
+		Path:       fmt.Sprintf("/v1/%s/network/network/%s", loc, id),
+		Operation:  "vpc.DeleteNetwork",
+		Location:   loc,
+		ResourceID: id.String(),
 	}
-	return c.API.JSONRequest(ctx, rc).Error
+	return api.DoNoContent(ctx, c.API, rc)
 }
 
 // RenameNetwork https://api.warren.io/#change-network-name
-func (c *Client) RenameNetwork(ctx context.Context, id uuid.UUID, newName string) error {
+func (c *Client) RenameNetwork(ctx context.Context, id uuid.UUID, newName string, opts ...api.CallOption) error {
+	loc, err := c.location(opts)
+	if err != nil {
+		return err
+	}
 	rc := api.RequestConfig{
-		Method: "PATCH",
-		Path:   fmt.Sprintf("/v1/%s/network/network/%s", c.Location, id),
-		JSON:   map[string]any{"name": newName},
+		Method:     "PATCH",
+		Path:       fmt.Sprintf("/v1/%s/network/network/%s", loc, id),
+		JSON:       map[string]any{"name": newName},
+		Operation:  "vpc.RenameNetwork",
+		Location:   loc,
+		ResourceID: id.String(),
 	}
-	return c.API.JSONRequest(ctx, rc).Error
+	return api.DoNoContent(ctx, c.API, rc)
 }
 
 // GetOrCreateDefaultNetwork https://api.warren.io/#create-or-get-default-network
-func (c *Client) GetOrCreateDefaultNetwork(ctx context.Context, name string) (NetworkInfo, error) {
-	var ni NetworkInfo
+func (c *Client) GetOrCreateDefaultNetwork(ctx context.Context, name string, opts ...api.CallOption) (NetworkInfo, error) {
+	return c.getOrCreateDefaultNetwork(ctx, name, "", opts)
+}
+
+func (c *Client) getOrCreateDefaultNetwork(ctx context.Context, name, idempotencyKey string, opts []api.CallOption) (NetworkInfo, error) {
+	loc, err := c.location(opts)
+	if err != nil {
+		return NetworkInfo{}, err
+	}
 	rc := api.RequestConfig{
 		Method: "POST",
-		Path:   fmt.Sprintf("/v1/%s/network/network", c.Location),
+		Path:   fmt.Sprintf("/v1/%s/network/network", loc),
 		Query:  url.Values{"name": []string{name}},
+		// the default network is only created when there's none
+		Retryable:      true,
+		IdempotencyKey: idempotencyKey,
+		Operation:      "vpc.GetOrCreateDefaultNetwork",
+		Location:       loc,
 	}
-	res := c.API.JSONRequest(ctx, rc)
-	if res.Error != nil {
-		return ni, res.Error
+	return api.Do[NetworkInfo](ctx, c.API, rc)
+}
+
+// EnsureDefaultNetwork returns the default network, creating it with given name when there's none.
+// Created reports whether it was created by this call.
+func (c *Client) EnsureDefaultNetwork(ctx context.Context, name string, opts ...api.CallOption) (NetworkInfo, bool, error) {
+	if n, ok, err := c.findDefaultNetwork(ctx, opts); err != nil || ok {
+		return n, false, err
+	}
+
+	n, err := c.getOrCreateDefaultNetwork(ctx, name, api.NewIdempotencyKey(), opts)
+	if err == nil {
+		return n, true, nil
 	}
-	if err := json.Unmarshal(res.Body, &ni); err != nil {
-		return ni, err
+	if !api.MayHaveSucceeded(err) {
+		return NetworkInfo{}, false, err
 	}
-	return ni, nil
+	found, ok, lerr := c.findDefaultNetwork(ctx, opts)
+	if lerr != nil || !ok {
+		return NetworkInfo{}, false, err
+	}
+	return found, true, nil
+}
+
+// findDefaultNetwork returns the default network
+func (c *Client) findDefaultNetwork(ctx context.Context, opts []api.CallOption) (NetworkInfo, bool, error) {
+	nets, err := c.ListNetworks(ctx, opts...)
+	if err != nil {
+		return NetworkInfo{}, false, err
+	}
+	for _, n := range nets {
+		if n.IsDefault {
+			return n, true, nil
+		}
+	}
+	return NetworkInfo{}, false, nil
 }
 
 // SetDefaultNetwork https://api.warren.io/#change-network-to-default
-func (c *Client) SetDefaultNetwork(ctx context.Context, id uuid.UUID) error {
+func (c *Client) SetDefaultNetwork(ctx context.Context, id uuid.UUID, opts ...api.CallOption) error {
+	loc, err := c.location(opts)
+	if err != nil {
+		return err
+	}
 	rc := api.RequestConfig{
-		Method: "PUT",
-		Path:   fmt.Sprintf("/v1/%s/network/network/%s/default", c.Location, id),
+		Method:     "PUT",
+		Path:       fmt.Sprintf("/v1/%s/network/network/%s/default", loc, id),
+		Operation:  "vpc.SetDefaultNetwork",
+		Location:   loc,
+		ResourceID: id.String(),
 	}
-	return c.API.JSONRequest(ctx, rc).Error
+	return api.DoNoContent(ctx, c.API, rc)
 }
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/ekaputra07/warren-go/api"
+	"github.com/ekaputra07/warren-go/internal/scopetest"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
@@ -86,3 +87,104 @@ func TestSetDefaultNetwork(t *testing.T) {
 	vpc := Client{API: a, Location: loc}
 	vpc.SetDefaultNetwork(context.Background(), id)
 }
+
+func TestGetNetwork_NotFound(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	defer s.Close()
+
+	vpc := Client{API: a, Location: loc}
+	_, err := vpc.GetNetwork(context.Background(), id)
+	assert.True(t, api.IsNotFound(err))
+}
+
+func TestDeleteNetwork_DryRun(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL)
+	})
+	defer s.Close()
+	a.DryRun = &api.Plan{}
+
+	vpc := Client{API: a, Location: loc}
+	err := vpc.DeleteNetwork(context.Background(), id)
+	assert.ErrorIs(t, err, api.ErrDryRun)
+	assert.Equal(t, []api.PlannedCall{{
+		Operation: "vpc.DeleteNetwork",
+		Method:    "DELETE",
+		Path:      fmt.Sprintf("/v1/%s/network/network/%s", loc, id),
+	}}, a.DryRun.Calls())
+}
+
+func TestWithLocation(t *testing.T) {
+	c := NewClient(api.Default, loc)
+	scoped := c.WithLocation("sgp01")
+	assert.Equal(t, "sgp01", scoped.Location)
+	assert.Equal(t, loc, c.Location)
+	assert.Same(t, c.API, scoped.API)
+}
+
+func TestListNetworks_InLocation(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/sgp01/network/networks", r.RequestURI)
+	})
+	defer s.Close()
+
+	vpc := Client{API: a}
+	_, err := vpc.ListNetworks(context.Background())
+	assert.ErrorIs(t, err, api.ErrMissingLocation)
+	_, err = vpc.ListNetworks(context.Background(), api.InLocation("sgp01"))
+	assert.NoError(t, err)
+}
+
+func TestListNetworks_ForBillingAccount(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request must not be sent")
+	})
+	defer s.Close()
+
+	vpc := NewClient(a, loc)
+	_, err := vpc.ListNetworks(context.Background(), api.ForBillingAccount(1))
+	assert.ErrorIs(t, err, api.ErrUnsupportedOption)
+}
+
+// run with -race to verify a shared client is safe to use with different scopes
+func TestClient_Concurrent(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(fmt.Sprintf(`[{"name": %q}]`, r.URL.Path)))
+	})
+	defer s.Close()
+	c := NewClient(a, loc)
+
+	check := func(s api.Scope, nets []NetworkInfo, err error) {
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("/v1/%s/network/networks", s.Location), nets[0].Name)
+	}
+	scopetest.Concurrent(
+		func(s api.Scope) {
+			nets, err := c.WithLocation(s.Location).ListNetworks(context.Background())
+			check(s, nets, err)
+		},
+		func(s api.Scope) {
+			nets, err := c.ListNetworks(context.Background(), api.InLocation(s.Location))
+			check(s, nets, err)
+		},
+	)
+	assert.Equal(t, loc, c.Location)
+}
+
+func TestEnsureDefaultNetwork_Failed(t *testing.T) {
+	a, s := api.MockClientServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`[{"name": "other"}]`))
+	})
+	defer s.Close()
+
+	vpc := NewClient(a, loc)
+	_, created, err := vpc.EnsureDefaultNetwork(context.Background(), "Default")
+	assert.True(t, api.HasStatus(err, http.StatusBadGateway))
+	assert.False(t, created)
+}
@@ -0,0 +1,146 @@
+// Package wait polls a resource until a condition holds, e.g. until a disk is attached
+// or a VM is running. Waiting stops when ctx is done, so the timeout is set on ctx:
+//
+//	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+//	defer cancel()
+//	d, err := blockstorage.WaitForDiskStatus(ctx, w.BlockStorage, id, "Attached", wait.Config{})
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Defaults of Config
+const (
+	DefaultInterval    = 2 * time.Second
+	DefaultMaxInterval = 30 * time.Second
+)
+
+// ErrTimeout is matched (using errors.Is) by TimeoutError
+var ErrTimeout = errors.New("timed out waiting")
+
+// TimeoutError is returned when ctx is done before the condition holds.
+// It also matches the ctx error, e.g. context.DeadlineExceeded.
+type TimeoutError struct {
+	// Description of the awaited condition
+	Description string
+	// LastState is state of the resource seen by the last successful poll, empty if there was none
+	LastState string
+	Attempts  int
+	Elapsed   time.Duration
+	Err       error
+}
+
+func (e *TimeoutError) Error() string {
+	last := e.LastState
+	if last == "" {
+		last = "nothing"
+	}
+	return fmt.Sprintf("%s for %s after %d attempts in %s, last seen: %s: %v",
+		ErrTimeout, e.Description, e.Attempts, e.Elapsed.Round(time.Millisecond), last, e.Err)
+}
+
+func (e *TimeoutError) Is(target error) bool {
+	return target == ErrTimeout
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// Progress is passed to Config.OnProgress after every successful poll
+type Progress struct {
+	Description string
+	State       string
+	Attempt     int
+	Elapsed     time.Duration
+}
+
+// Config configures polling, the zero value polls every DefaultInterval.
+type Config struct {
+	// Interval is delay between the first polls, DefaultInterval if zero
+	Interval time.Duration
+	// Backoff multiplies the interval after every poll, constant interval if <= 1
+	Backoff float64
+	// MaxInterval caps the interval growing by Backoff, DefaultMaxInterval if zero
+	MaxInterval time.Duration
+	// OnProgress is called after every successful poll, it must not block.
+	OnProgress func(Progress)
+}
+
+// next returns interval following d
+func (c Config) next(d time.Duration) time.Duration {
+	if c.Backoff <= 1 {
+		return d
+	}
+	max := c.MaxInterval
+	if max <= 0 {
+		max = DefaultMaxInterval
+	}
+	d = time.Duration(float64(d) * c.Backoff)
+	if d > max {
+		return max
+	}
+	return d
+}
+
+// Condition describes what's awaited
+type Condition[T any] struct {
+	// Description is used in progress and errors, e.g. `disk 1234 to be Attached`
+	Description string
+	// Poll fetches the resource, its error stops waiting unless ctx is done.
+	Poll func(ctx context.Context) (T, error)
+	// Done reports whether the condition holds
+	Done func(T) bool
+	// State describes the resource in progress and errors, e.g. its status
+	State func(T) string
+}
+
+// Until polls until the condition holds and returns the final resource.
+// When ctx is done first, the last polled resource is returned with *TimeoutError.
+func Until[T any](ctx context.Context, cfg Config, c Condition[T]) (T, error) {
+	start := time.Now()
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	var last T
+	var state string
+	for attempt := 1; ; attempt++ {
+		v, err := c.Poll(ctx)
+		if err != nil && ctx.Err() == nil {
+			return v, err
+		}
+		if err == nil {
+			last = v
+			if c.State != nil {
+				state = c.State(v)
+			}
+			if cfg.OnProgress != nil {
+				cfg.OnProgress(Progress{Description: c.Description, State: state, Attempt: attempt, Elapsed: time.Since(start)})
+			}
+			if c.Done(v) {
+				return v, nil
+			}
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return last, &TimeoutError{
+				Description: c.Description,
+				LastState:   state,
+				Attempts:    attempt,
+				Elapsed:     time.Since(start),
+				Err:         ctx.Err(),
+			}
+		case <-timer.C:
+		}
+		interval = cfg.next(interval)
+	}
+}
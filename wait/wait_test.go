@@ -0,0 +1,81 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// counter returns condition polling increasing numbers until n is reached
+func counter(n int) Condition[int] {
+	i := 0
+	return Condition[int]{
+		Description: fmt.Sprintf("counter to reach %d", n),
+		Poll: func(ctx context.Context) (int, error) {
+			i++
+			return i, nil
+		},
+		Done:  func(i int) bool { return i >= n },
+		State: func(i int) string { return fmt.Sprintf("count %d", i) },
+	}
+}
+
+func TestUntil(t *testing.T) {
+	var progress []Progress
+	cfg := Config{
+		Interval:   time.Millisecond,
+		OnProgress: func(p Progress) { progress = append(progress, p) },
+	}
+
+	v, err := Until(context.Background(), cfg, counter(3))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, v)
+	assert.Len(t, progress, 3)
+	assert.Equal(t, "count 2", progress[1].State)
+	assert.Equal(t, 2, progress[1].Attempt)
+	assert.Equal(t, "counter to reach 3", progress[1].Description)
+}
+
+func TestUntil_Timeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	v, err := Until(ctx, Config{Interval: 5 * time.Millisecond}, counter(1000))
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	var timeout *TimeoutError
+	assert.ErrorAs(t, err, &timeout)
+	assert.Equal(t, v, timeout.Attempts)
+	assert.Equal(t, fmt.Sprintf("count %d", v), timeout.LastState)
+	assert.ErrorContains(t, err, "counter to reach 1000")
+	assert.ErrorContains(t, err, fmt.Sprintf("last seen: count %d", v))
+}
+
+func TestUntil_PollError(t *testing.T) {
+	errBoom := errors.New("boom")
+	c := counter(3)
+	c.Poll = func(ctx context.Context) (int, error) { return 0, errBoom }
+
+	_, err := Until(context.Background(), Config{Interval: time.Millisecond}, c)
+	assert.ErrorIs(t, err, errBoom)
+	assert.NotErrorIs(t, err, ErrTimeout)
+}
+
+func TestConfig_Backoff(t *testing.T) {
+	cfg := Config{Backoff: 2, MaxInterval: 5 * time.Second}
+	d := time.Second
+	var got []time.Duration
+	for i := 0; i < 4; i++ {
+		d = cfg.next(d)
+		got = append(got, d)
+	}
+	assert.Equal(t, []time.Duration{2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}, got)
+
+	// constant interval
+	assert.Equal(t, time.Second, Config{}.next(time.Second))
+}
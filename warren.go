@@ -6,26 +6,37 @@ import (
 	"github.com/ekaputra07/warren-go/ip"
 	"github.com/ekaputra07/warren-go/location"
 	"github.com/ekaputra07/warren-go/objectstorage"
+	"github.com/ekaputra07/warren-go/vm"
 	"github.com/ekaputra07/warren-go/vpc"
 )
 
-// Warren a single object to access all APIs
+// Warren a single object to access all APIs.
+// The fields are interfaces, so each of them can be replaced with a fake in tests (see warrentest package).
+// Warren and all its clients are safe for concurrent use.
 type Warren struct {
-	Location      *location.Client
-	ObjectStorage *objectstorage.Client
-	BlockStorage  *blockstorage.Client
-	VPC           *vpc.Client
-	IP            *ip.Client
+	Location      location.Service
+	ObjectStorage objectstorage.Service
+	BlockStorage  blockstorage.Service
+	VPC           vpc.Service
+	IP            ip.Service
+	VM            vm.Service
 }
 
 // Init initialize Warren with given API client
-func Init(api *api.API, loc string) *Warren {
+func Init(a *api.API, loc string) *Warren {
+	return InitWithScope(a, api.Scope{Location: loc})
+}
+
+// InitWithScope initialize Warren with given API client, the clients use location and billing account
+// of the scope by default.
+func InitWithScope(a *api.API, s api.Scope) *Warren {
 	return &Warren{
-		Location:      location.NewClient(api),
-		ObjectStorage: objectstorage.NewClient(api),
-		BlockStorage:  blockstorage.NewClient(api),
-		VPC:           vpc.NewClient(api, loc),
-		IP:            ip.NewClient(api, loc),
+		Location:      location.NewClient(a),
+		ObjectStorage: objectstorage.NewClient(a).WithBillingAccount(s.BillingAccountID),
+		BlockStorage:  blockstorage.NewClient(a).WithBillingAccount(s.BillingAccountID),
+		VPC:           vpc.NewClient(a, s.Location),
+		IP:            ip.NewClient(a, s.Location).WithBillingAccount(s.BillingAccountID),
+		VM:            vm.NewClient(a, s.Location).WithBillingAccount(s.BillingAccountID),
 	}
 }
 
@@ -38,7 +49,7 @@ func New() *Warren {
 
 // New returns Warren that initialized with Default API client and specified location.
 // Use this if you want to manage resources that require datacenter location such as:
-// vpc, ip
+// vpc, ip, vm
 func NewWithLocation(location string) *Warren {
 	return Init(api.Default, location)
 }
@@ -0,0 +1,124 @@
+package warren
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ekaputra07/warren-go/api"
+	"github.com/ekaputra07/warren-go/blockstorage"
+	"github.com/ekaputra07/warren-go/ip"
+	"github.com/ekaputra07/warren-go/location"
+	"github.com/ekaputra07/warren-go/objectstorage"
+	"github.com/ekaputra07/warren-go/vm"
+	"github.com/ekaputra07/warren-go/vpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewClient(t *testing.T) {
+	hc := &http.Client{}
+	plan := &api.Plan{}
+	cache := api.NewCache(map[string]time.Duration{"/v1/config/locations": time.Hour})
+	breaker := api.NewCircuitBreaker(0.5, time.Minute)
+	retry := api.DefaultBackoff()
+	limiter := api.NewRateLimiter(api.RateLimit{RPS: 10, Burst: 1}, nil)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	tracing := &api.Tracing{}
+	mw := func(next api.Handler) api.Handler { return next }
+	w, err := NewClient(
+		WithBaseURL("https://api.warren.io"),
+		WithAPIKey("secret"),
+		WithHTTPClient(hc),
+		WithLocation("jkt01"),
+		WithBillingAccount(123),
+		WithUserAgent("my-app/1.0"),
+		WithTimeout(5*time.Second),
+		WithDryRun(plan),
+		WithCache(cache),
+		WithCircuitBreaker(breaker),
+		WithIdempotencyHeader(api.IdempotencyKeyHeader),
+		WithRetryPolicy(retry),
+		WithRateLimiter(limiter),
+		WithLogger(logger),
+		WithTracing(tracing),
+		WithStrictDecoding(),
+		WithMiddleware(mw),
+	)
+	assert.NoError(t, err)
+
+	a := w.VPC.(*vpc.Client).API
+	assert.Equal(t, "https://api.warren.io", a.BaseURL)
+	assert.Equal(t, "secret", a.APIKey)
+	assert.Equal(t, "my-app/1.0", a.UserAgent)
+	assert.Equal(t, 5*time.Second, a.HTTPClient.Timeout)
+	assert.Equal(t, time.Duration(0), hc.Timeout)
+	assert.Same(t, plan, a.DryRun)
+	assert.Same(t, cache, a.Cache)
+	assert.Same(t, breaker, a.CircuitBreaker)
+	assert.Equal(t, api.IdempotencyKeyHeader, a.IdempotencyHeader)
+	assert.Same(t, retry, a.RetryPolicy)
+	assert.Same(t, limiter, a.RateLimiter)
+	assert.Same(t, logger, a.Logger)
+	assert.Same(t, tracing, a.Tracing)
+	assert.True(t, a.StrictDecoding)
+	assert.Len(t, a.Middlewares, 1)
+	assert.Equal(t, "jkt01", w.VPC.(*vpc.Client).Location)
+	assert.Equal(t, "jkt01", w.IP.(*ip.Client).Location)
+	assert.Equal(t, "jkt01", w.VM.(*vm.Client).Location)
+	assert.Equal(t, 123, w.ObjectStorage.(*objectstorage.Client).BillingAccountID)
+	assert.Equal(t, 123, w.BlockStorage.(*blockstorage.Client).BillingAccountID)
+	assert.Equal(t, 123, w.IP.(*ip.Client).BillingAccountID)
+	assert.Equal(t, 123, w.VM.(*vm.Client).BillingAccountID)
+}
+
+func TestNewClient_Env(t *testing.T) {
+	t.Setenv(api.BaseURLEnvKey, "https://api.warren.io")
+	t.Setenv(api.APIKeyEnvKey, "secret")
+
+	w, err := NewClient()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.warren.io", w.Location.(*location.Client).API.BaseURL)
+	assert.Equal(t, http.DefaultClient, w.Location.(*location.Client).API.HTTPClient)
+}
+
+func TestNewClient_Invalid(t *testing.T) {
+	t.Setenv(api.BaseURLEnvKey, "")
+	t.Setenv(api.APIKeyEnvKey, "")
+
+	valid := []Option{WithBaseURL("https://api.warren.io"), WithAPIKey("secret")}
+	cases := map[string][]Option{
+		"no base URL":      {WithAPIKey("secret")},
+		"invalid base URL": {WithBaseURL("api.warren.io"), WithAPIKey("secret")},
+		"no API key":       {WithBaseURL("https://api.warren.io")},
+		"empty location":   append(valid, WithLocation("")),
+		"billing account":  append(valid, WithBillingAccount(0)),
+		"timeout":          append(valid, WithTimeout(-time.Second)),
+		"http client":      append(valid, WithHTTPClient(nil)),
+		"dry run":          append(valid, WithDryRun(nil)),
+		"cache":            append(valid, WithCache(nil)),
+		"circuit breaker":  append(valid, WithCircuitBreaker(nil)),
+		"idempotency":      append(valid, WithIdempotencyHeader("")),
+		"retry policy":     append(valid, WithRetryPolicy(nil)),
+		"rate limiter":     append(valid, WithRateLimiter(nil)),
+		"logger":           append(valid, WithLogger(nil)),
+		"tracing":          append(valid, WithTracing(nil)),
+	}
+	for name, opts := range cases {
+		_, err := NewClient(opts...)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestNewClient_NoLocation(t *testing.T) {
+	w, err := NewClient(WithBaseURL("https://api.warren.io"), WithAPIKey("secret"))
+	assert.NoError(t, err)
+
+	_, err = w.VPC.ListNetworks(context.Background())
+	assert.ErrorIs(t, err, api.ErrMissingLocation)
+
+	_, err = w.IP.ListFloatingIPs(context.Background())
+	assert.ErrorIs(t, err, api.ErrMissingLocation)
+}
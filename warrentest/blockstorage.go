@@ -0,0 +1,178 @@
+package warrentest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/ekaputra07/warren-go/blockstorage"
+	"github.com/google/uuid"
+)
+
+// Disk statuses used by the server
+const (
+	DiskStatusDetached = "Detached"
+	DiskStatusAttached = "Attached"
+)
+
+// disk is blockstorage.Disk with the VM it's attached to
+type disk struct {
+	blockstorage.Disk
+	vm  uuid.NullUUID
+	seq int
+}
+
+var sourceImageTypes = map[string]bool{"OS_BASE": true, "DISK": true, "SNAPSHOT": true, "EMPTY": true}
+
+// findDisk returns disk with given id, writes 404 response if not found.
+func (s *Server) findDisk(w http.ResponseWriter, id string) (*disk, bool) {
+	u, ok := parseUUID(w, id)
+	if !ok {
+		return nil, false
+	}
+	d, ok := s.disks[u]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("disk %s not found", id))
+		return nil, false
+	}
+	return d, true
+}
+
+func (s *Server) listDisks(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	disks := []*disk{}
+	for _, d := range s.disks {
+		disks = append(disks, d)
+	}
+	sort.Slice(disks, func(i, j int) bool { return disks[i].seq < disks[j].seq })
+	writeJSON(w, http.StatusOK, disks)
+}
+
+func (s *Server) createDisk(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	_ = r.ParseForm()
+	size, err := strconv.Atoi(r.Form.Get("size_gb"))
+	if err != nil || size <= 0 {
+		writeError(w, http.StatusBadRequest, "size_gb is invalid")
+		return
+	}
+	billingAccountID, _ := strconv.Atoi(r.Form.Get("billing_account_id"))
+	if billingAccountID == 0 {
+		billingAccountID = BillingAccountID
+	}
+	imageType := r.Form.Get("source_image_type")
+	if imageType == "" {
+		imageType = "EMPTY"
+	}
+	if !sourceImageTypes[imageType] {
+		writeError(w, http.StatusBadRequest, "source_image_type is invalid")
+		return
+	}
+
+	d := s.newDisk(size, billingAccountID, imageType, r.Form.Get("source_image"))
+	writeJSON(w, http.StatusOK, d.Disk)
+}
+
+func (s *Server) getDisk(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	if d, ok := s.findDisk(w, p["id"]); ok {
+		writeJSON(w, http.StatusOK, d.Disk)
+	}
+}
+
+func (s *Server) updateDisk(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	d, ok := s.findDisk(w, p["id"])
+	if !ok {
+		return
+	}
+	_ = r.ParseForm()
+	billingAccountID, err := strconv.Atoi(r.Form.Get("billing_account_id"))
+	if err != nil || billingAccountID <= 0 {
+		writeError(w, http.StatusBadRequest, "billing_account_id is invalid")
+		return
+	}
+	d.BillingAccountID = billingAccountID
+	d.UpdatedAt = now()
+	writeJSON(w, http.StatusOK, d.Disk)
+}
+
+func (s *Server) deleteDisk(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	d, ok := s.findDisk(w, p["id"])
+	if !ok {
+		return
+	}
+	if d.vm.Valid {
+		writeError(w, http.StatusConflict, "disk is attached, detach it first")
+		return
+	}
+	delete(s.disks, d.UUID)
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+// diskAndVM parses attach/detach form payload
+func (s *Server) diskAndVM(w http.ResponseWriter, r *http.Request) (*disk, uuid.UUID, bool) {
+	_ = r.ParseForm()
+	vmID, err := uuid.Parse(r.Form.Get("uuid"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "uuid is invalid")
+		return nil, uuid.Nil, false
+	}
+	d, ok := s.findDisk(w, r.Form.Get("storage_uuid"))
+	return d, vmID, ok
+}
+
+func (s *Server) attachDisk(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	d, vmID, ok := s.diskAndVM(w, r)
+	if !ok {
+		return
+	}
+	if d.vm.Valid {
+		writeError(w, http.StatusConflict, fmt.Sprintf("disk is already attached to %s", d.vm.UUID))
+		return
+	}
+	d.vm = uuid.NullUUID{UUID: vmID, Valid: true}
+	d.Status = DiskStatusAttached
+	d.UpdatedAt = now()
+	writeJSON(w, http.StatusOK, d.Disk)
+}
+
+func (s *Server) detachDisk(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	d, vmID, ok := s.diskAndVM(w, r)
+	if !ok {
+		return
+	}
+	if !d.vm.Valid || d.vm.UUID != vmID {
+		writeError(w, http.StatusConflict, fmt.Sprintf("disk is not attached to %s", vmID))
+		return
+	}
+	d.vm = uuid.NullUUID{}
+	d.Status = DiskStatusDetached
+	d.UpdatedAt = now()
+	writeJSON(w, http.StatusOK, d.Disk)
+}
+
+// AddDisk adds an empty detached disk, useful to seed the server state.
+func (s *Server) AddDisk(sizeGB int) uuid.UUID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.newDisk(sizeGB, BillingAccountID, "EMPTY", "").UUID
+}
+
+// newDisk creates and stores a new detached disk
+func (s *Server) newDisk(sizeGB, billingAccountID int, imageType, image string) *disk {
+	d := &disk{
+		Disk: blockstorage.Disk{
+			UUID:             uuid.New(),
+			Status:           DiskStatusDetached,
+			Snapshots:        []blockstorage.Snapshot{},
+			UserID:           UserID,
+			BillingAccountID: billingAccountID,
+			SizeGB:           sizeGB,
+			SourceImageType:  blockstorage.SourceImageType(imageType),
+			SourceImage:      image,
+			CreatedAt:        now(),
+			UpdatedAt:        now(),
+		},
+		seq: s.id(),
+	}
+	s.disks[d.UUID] = d
+	return d
+}
@@ -0,0 +1,554 @@
+package warrentest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ekaputra07/warren-go/api"
+	"github.com/ekaputra07/warren-go/blockstorage"
+	"github.com/ekaputra07/warren-go/ip"
+	"github.com/ekaputra07/warren-go/location"
+	"github.com/ekaputra07/warren-go/objectstorage"
+	"github.com/ekaputra07/warren-go/vm"
+	"github.com/ekaputra07/warren-go/vpc"
+	"github.com/google/uuid"
+)
+
+// Call is a call recorded by a fake
+type Call struct {
+	Method string
+	Args   []any
+	// Scope is the scope set by call options, fields not overridden are zero.
+	Scope api.Scope
+}
+
+// CallRecorder records calls, it's embedded in every fake
+type CallRecorder struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+func (r *CallRecorder) record(method string, opts []api.CallOption, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, Call{Method: method, Args: args, Scope: api.ResolveScope(api.Scope{}, opts)})
+}
+
+// Calls returns all recorded calls in order, the context argument is omitted and call options are resolved to Call.Scope.
+func (r *CallRecorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call{}, r.calls...)
+}
+
+// CallsTo returns recorded calls of given method
+func (r *CallRecorder) CallsTo(method string) []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	calls := []Call{}
+	for _, c := range r.calls {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Reset forgets all recorded calls
+func (r *CallRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = nil
+}
+
+// FakeLocation is configurable location.Service, methods without a func set return zero values.
+type FakeLocation struct {
+	CallRecorder
+	ListLocationsFunc func(ctx context.Context, opts ...api.CallOption) ([]location.Location, error)
+}
+
+var _ location.Service = (*FakeLocation)(nil)
+
+func (f *FakeLocation) ListLocations(ctx context.Context, opts ...api.CallOption) ([]location.Location, error) {
+	f.record("ListLocations", opts)
+	if f.ListLocationsFunc != nil {
+		return f.ListLocationsFunc(ctx, opts...)
+	}
+	return nil, nil
+}
+
+// FakeVPC is configurable vpc.Service, methods without a func set return zero values.
+type FakeVPC struct {
+	CallRecorder
+	ListNetworksFunc              func(ctx context.Context, opts ...api.CallOption) ([]vpc.NetworkInfo, error)
+	GetNetworkFunc                func(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (vpc.NetworkInfo, error)
+	DeleteNetworkFunc             func(ctx context.Context, id uuid.UUID, opts ...api.CallOption) error
+	RenameNetworkFunc             func(ctx context.Context, id uuid.UUID, newName string, opts ...api.CallOption) error
+	GetOrCreateDefaultNetworkFunc func(ctx context.Context, name string, opts ...api.CallOption) (vpc.NetworkInfo, error)
+	EnsureDefaultNetworkFunc      func(ctx context.Context, name string, opts ...api.CallOption) (vpc.NetworkInfo, bool, error)
+	SetDefaultNetworkFunc         func(ctx context.Context, id uuid.UUID, opts ...api.CallOption) error
+}
+
+var _ vpc.Service = (*FakeVPC)(nil)
+
+func (f *FakeVPC) ListNetworks(ctx context.Context, opts ...api.CallOption) ([]vpc.NetworkInfo, error) {
+	f.record("ListNetworks", opts)
+	if f.ListNetworksFunc != nil {
+		return f.ListNetworksFunc(ctx, opts...)
+	}
+	return nil, nil
+}
+
+func (f *FakeVPC) GetNetwork(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (vpc.NetworkInfo, error) {
+	f.record("GetNetwork", opts, id)
+	if f.GetNetworkFunc != nil {
+		return f.GetNetworkFunc(ctx, id, opts...)
+	}
+	return vpc.NetworkInfo{}, nil
+}
+
+func (f *FakeVPC) DeleteNetwork(ctx context.Context, id uuid.UUID, opts ...api.CallOption) error {
+	f.record("DeleteNetwork", opts, id)
+	if f.DeleteNetworkFunc != nil {
+		return f.DeleteNetworkFunc(ctx, id, opts...)
+	}
+	return nil
+}
+
+func (f *FakeVPC) RenameNetwork(ctx context.Context, id uuid.UUID, newName string, opts ...api.CallOption) error {
+	f.record("RenameNetwork", opts, id, newName)
+	if f.RenameNetworkFunc != nil {
+		return f.RenameNetworkFunc(ctx, id, newName, opts...)
+	}
+	return nil
+}
+
+func (f *FakeVPC) GetOrCreateDefaultNetwork(ctx context.Context, name string, opts ...api.CallOption) (vpc.NetworkInfo, error) {
+	f.record("GetOrCreateDefaultNetwork", opts, name)
+	if f.GetOrCreateDefaultNetworkFunc != nil {
+		return f.GetOrCreateDefaultNetworkFunc(ctx, name, opts...)
+	}
+	return vpc.NetworkInfo{}, nil
+}
+
+func (f *FakeVPC) EnsureDefaultNetwork(ctx context.Context, name string, opts ...api.CallOption) (vpc.NetworkInfo, bool, error) {
+	f.record("EnsureDefaultNetwork", opts, name)
+	if f.EnsureDefaultNetworkFunc != nil {
+		return f.EnsureDefaultNetworkFunc(ctx, name, opts...)
+	}
+	return vpc.NetworkInfo{}, false, nil
+}
+
+func (f *FakeVPC) SetDefaultNetwork(ctx context.Context, id uuid.UUID, opts ...api.CallOption) error {
+	f.record("SetDefaultNetwork", opts, id)
+	if f.SetDefaultNetworkFunc != nil {
+		return f.SetDefaultNetworkFunc(ctx, id, opts...)
+	}
+	return nil
+}
+
+// FakeIP is configurable ip.Service, methods without a func set return zero values.
+type FakeIP struct {
+	CallRecorder
+	ListFloatingIPsFunc          func(ctx context.Context, opts ...api.CallOption) ([]ip.IPAddressInfo, error)
+	CreateFloatingIPFunc         func(ctx context.Context, info *ip.IPAddressInfo, opts ...api.CallOption) error
+	EnsureFloatingIPFunc         func(ctx context.Context, info ip.IPAddressInfo, opts ...api.CallOption) (ip.IPAddressInfo, bool, error)
+	GetFloatingIPFunc            func(ctx context.Context, address string, opts ...api.CallOption) (ip.IPAddressInfo, error)
+	UpdateFloatingIPFunc         func(ctx context.Context, info ip.IPAddressInfo, opts ...api.CallOption) error
+	DeleteFloatingIPFunc         func(ctx context.Context, address string, opts ...api.CallOption) error
+	AssignFloatingIPToVMFunc     func(ctx context.Context, address string, vmUUID uuid.UUID, opts ...api.CallOption) error
+	UnassignFloatingIPFromVMFunc func(ctx context.Context, address string, vmUUID uuid.UUID, opts ...api.CallOption) error
+}
+
+var _ ip.Service = (*FakeIP)(nil)
+
+func (f *FakeIP) ListFloatingIPs(ctx context.Context, opts ...api.CallOption) ([]ip.IPAddressInfo, error) {
+	f.record("ListFloatingIPs", opts)
+	if f.ListFloatingIPsFunc != nil {
+		return f.ListFloatingIPsFunc(ctx, opts...)
+	}
+	return nil, nil
+}
+
+func (f *FakeIP) CreateFloatingIP(ctx context.Context, info *ip.IPAddressInfo, opts ...api.CallOption) error {
+	f.record("CreateFloatingIP", opts, info)
+	if f.CreateFloatingIPFunc != nil {
+		return f.CreateFloatingIPFunc(ctx, info, opts...)
+	}
+	return nil
+}
+
+func (f *FakeIP) EnsureFloatingIP(ctx context.Context, info ip.IPAddressInfo, opts ...api.CallOption) (ip.IPAddressInfo, bool, error) {
+	f.record("EnsureFloatingIP", opts, info)
+	if f.EnsureFloatingIPFunc != nil {
+		return f.EnsureFloatingIPFunc(ctx, info, opts...)
+	}
+	return ip.IPAddressInfo{}, false, nil
+}
+
+func (f *FakeIP) GetFloatingIP(ctx context.Context, address string, opts ...api.CallOption) (ip.IPAddressInfo, error) {
+	f.record("GetFloatingIP", opts, address)
+	if f.GetFloatingIPFunc != nil {
+		return f.GetFloatingIPFunc(ctx, address, opts...)
+	}
+	return ip.IPAddressInfo{}, nil
+}
+
+func (f *FakeIP) UpdateFloatingIP(ctx context.Context, info ip.IPAddressInfo, opts ...api.CallOption) error {
+	f.record("UpdateFloatingIP", opts, info)
+	if f.UpdateFloatingIPFunc != nil {
+		return f.UpdateFloatingIPFunc(ctx, info, opts...)
+	}
+	return nil
+}
+
+func (f *FakeIP) DeleteFloatingIP(ctx context.Context, address string, opts ...api.CallOption) error {
+	f.record("DeleteFloatingIP", opts, address)
+	if f.DeleteFloatingIPFunc != nil {
+		return f.DeleteFloatingIPFunc(ctx, address, opts...)
+	}
+	return nil
+}
+
+func (f *FakeIP) AssignFloatingIPToVM(ctx context.Context, address string, vmUUID uuid.UUID, opts ...api.CallOption) error {
+	f.record("AssignFloatingIPToVM", opts, address, vmUUID)
+	if f.AssignFloatingIPToVMFunc != nil {
+		return f.AssignFloatingIPToVMFunc(ctx, address, vmUUID, opts...)
+	}
+	return nil
+}
+
+func (f *FakeIP) UnassignFloatingIPFromVM(ctx context.Context, address string, vmUUID uuid.UUID, opts ...api.CallOption) error {
+	f.record("UnassignFloatingIPFromVM", opts, address, vmUUID)
+	if f.UnassignFloatingIPFromVMFunc != nil {
+		return f.UnassignFloatingIPFromVMFunc(ctx, address, vmUUID, opts...)
+	}
+	return nil
+}
+
+// FakeVM is configurable vm.Service, methods without a func set return zero values.
+type FakeVM struct {
+	CallRecorder
+	ListVMsFunc        func(ctx context.Context, opts ...api.CallOption) ([]vm.VM, error)
+	GetVMFunc          func(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (vm.VM, error)
+	CreateVMFunc       func(ctx context.Context, spec vm.CreateVMSpec, opts ...api.CallOption) (vm.VM, error)
+	StartFunc          func(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (vm.VM, error)
+	StopFunc           func(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (vm.VM, error)
+	RebootFunc         func(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (vm.VM, error)
+	ForceStopFunc      func(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (vm.VM, error)
+	PerformActionFunc  func(ctx context.Context, id uuid.UUID, action vm.Action, opts ...api.CallOption) (vm.VM, error)
+	DeleteFunc         func(ctx context.Context, id uuid.UUID, opts ...api.CallOption) error
+	GetLimitsFunc      func(ctx context.Context, opts ...api.CallOption) (vm.Limits, error)
+	ResizeFunc         func(ctx context.Context, id uuid.UUID, vcpu, ramMB int, opts ...api.CallOption) (vm.ResizeResult, error)
+	EnableBackupsFunc  func(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (vm.VM, error)
+	DisableBackupsFunc func(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (vm.VM, error)
+	ListBackupsFunc    func(ctx context.Context, id uuid.UUID, opts ...api.CallOption) ([]vm.Backup, error)
+	RestoreBackupFunc  func(ctx context.Context, id, backupID uuid.UUID, opts ...api.CallOption) (vm.VM, error)
+}
+
+var _ vm.Service = (*FakeVM)(nil)
+
+func (f *FakeVM) ListVMs(ctx context.Context, opts ...api.CallOption) ([]vm.VM, error) {
+	f.record("ListVMs", opts)
+	if f.ListVMsFunc != nil {
+		return f.ListVMsFunc(ctx, opts...)
+	}
+	return nil, nil
+}
+
+func (f *FakeVM) GetVM(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (vm.VM, error) {
+	f.record("GetVM", opts, id)
+	if f.GetVMFunc != nil {
+		return f.GetVMFunc(ctx, id, opts...)
+	}
+	return vm.VM{}, nil
+}
+
+func (f *FakeVM) CreateVM(ctx context.Context, spec vm.CreateVMSpec, opts ...api.CallOption) (vm.VM, error) {
+	f.record("CreateVM", opts, spec)
+	if f.CreateVMFunc != nil {
+		return f.CreateVMFunc(ctx, spec, opts...)
+	}
+	return vm.VM{}, nil
+}
+
+func (f *FakeVM) Start(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (vm.VM, error) {
+	f.record("Start", opts, id)
+	if f.StartFunc != nil {
+		return f.StartFunc(ctx, id, opts...)
+	}
+	return vm.VM{}, nil
+}
+
+func (f *FakeVM) Stop(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (vm.VM, error) {
+	f.record("Stop", opts, id)
+	if f.StopFunc != nil {
+		return f.StopFunc(ctx, id, opts...)
+	}
+	return vm.VM{}, nil
+}
+
+func (f *FakeVM) Reboot(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (vm.VM, error) {
+	f.record("Reboot", opts, id)
+	if f.RebootFunc != nil {
+		return f.RebootFunc(ctx, id, opts...)
+	}
+	return vm.VM{}, nil
+}
+
+func (f *FakeVM) ForceStop(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (vm.VM, error) {
+	f.record("ForceStop", opts, id)
+	if f.ForceStopFunc != nil {
+		return f.ForceStopFunc(ctx, id, opts...)
+	}
+	return vm.VM{}, nil
+}
+
+func (f *FakeVM) PerformAction(ctx context.Context, id uuid.UUID, action vm.Action, opts ...api.CallOption) (vm.VM, error) {
+	f.record("PerformAction", opts, id, action)
+	if f.PerformActionFunc != nil {
+		return f.PerformActionFunc(ctx, id, action, opts...)
+	}
+	return vm.VM{}, nil
+}
+
+func (f *FakeVM) Delete(ctx context.Context, id uuid.UUID, opts ...api.CallOption) error {
+	f.record("Delete", opts, id)
+	if f.DeleteFunc != nil {
+		return f.DeleteFunc(ctx, id, opts...)
+	}
+	return nil
+}
+
+func (f *FakeVM) GetLimits(ctx context.Context, opts ...api.CallOption) (vm.Limits, error) {
+	f.record("GetLimits", opts)
+	if f.GetLimitsFunc != nil {
+		return f.GetLimitsFunc(ctx, opts...)
+	}
+	return vm.Limits{}, nil
+}
+
+func (f *FakeVM) Resize(ctx context.Context, id uuid.UUID, vcpu, ramMB int, opts ...api.CallOption) (vm.ResizeResult, error) {
+	f.record("Resize", opts, id, vcpu, ramMB)
+	if f.ResizeFunc != nil {
+		return f.ResizeFunc(ctx, id, vcpu, ramMB, opts...)
+	}
+	return vm.ResizeResult{}, nil
+}
+
+func (f *FakeVM) EnableBackups(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (vm.VM, error) {
+	f.record("EnableBackups", opts, id)
+	if f.EnableBackupsFunc != nil {
+		return f.EnableBackupsFunc(ctx, id, opts...)
+	}
+	return vm.VM{}, nil
+}
+
+func (f *FakeVM) DisableBackups(ctx context.Context, id uuid.UUID, opts ...api.CallOption) (vm.VM, error) {
+	f.record("DisableBackups", opts, id)
+	if f.DisableBackupsFunc != nil {
+		return f.DisableBackupsFunc(ctx, id, opts...)
+	}
+	return vm.VM{}, nil
+}
+
+func (f *FakeVM) ListBackups(ctx context.Context, id uuid.UUID, opts ...api.CallOption) ([]vm.Backup, error) {
+	f.record("ListBackups", opts, id)
+	if f.ListBackupsFunc != nil {
+		return f.ListBackupsFunc(ctx, id, opts...)
+	}
+	return nil, nil
+}
+
+func (f *FakeVM) RestoreBackup(ctx context.Context, id, backupID uuid.UUID, opts ...api.CallOption) (vm.VM, error) {
+	f.record("RestoreBackup", opts, id, backupID)
+	if f.RestoreBackupFunc != nil {
+		return f.RestoreBackupFunc(ctx, id, backupID, opts...)
+	}
+	return vm.VM{}, nil
+}
+
+// FakeBlockStorage is configurable blockstorage.Service, methods without a func set return zero values.
+type FakeBlockStorage struct {
+	CallRecorder
+	ListDisksFunc                func(ctx context.Context, opts ...api.CallOption) ([]blockstorage.Disk, error)
+	CreateDiskFunc               func(ctx context.Context, disk *blockstorage.Disk, opts ...api.CallOption) error
+	EnsureDiskFunc               func(ctx context.Context, disk blockstorage.Disk, opts ...api.CallOption) (blockstorage.Disk, bool, error)
+	GetDiskFunc                  func(ctx context.Context, diskID uuid.UUID, opts ...api.CallOption) (blockstorage.Disk, error)
+	DeleteDiskFunc               func(ctx context.Context, diskID uuid.UUID, opts ...api.CallOption) error
+	AttachDiskToVMFunc           func(ctx context.Context, diskID, vmID uuid.UUID, opts ...api.CallOption) error
+	DetachDiskFromVMFunc         func(ctx context.Context, diskID, vmID uuid.UUID, opts ...api.CallOption) error
+	UpdateDiskBillingAccountFunc func(ctx context.Context, diskID uuid.UUID, billingAccountID int, opts ...api.CallOption) error
+}
+
+var _ blockstorage.Service = (*FakeBlockStorage)(nil)
+
+func (f *FakeBlockStorage) ListDisks(ctx context.Context, opts ...api.CallOption) ([]blockstorage.Disk, error) {
+	f.record("ListDisks", opts)
+	if f.ListDisksFunc != nil {
+		return f.ListDisksFunc(ctx, opts...)
+	}
+	return nil, nil
+}
+
+func (f *FakeBlockStorage) CreateDisk(ctx context.Context, disk *blockstorage.Disk, opts ...api.CallOption) error {
+	f.record("CreateDisk", opts, disk)
+	if f.CreateDiskFunc != nil {
+		return f.CreateDiskFunc(ctx, disk, opts...)
+	}
+	return nil
+}
+
+func (f *FakeBlockStorage) EnsureDisk(ctx context.Context, disk blockstorage.Disk, opts ...api.CallOption) (blockstorage.Disk, bool, error) {
+	f.record("EnsureDisk", opts, disk)
+	if f.EnsureDiskFunc != nil {
+		return f.EnsureDiskFunc(ctx, disk, opts...)
+	}
+	return blockstorage.Disk{}, false, nil
+}
+
+func (f *FakeBlockStorage) GetDisk(ctx context.Context, diskID uuid.UUID, opts ...api.CallOption) (blockstorage.Disk, error) {
+	f.record("GetDisk", opts, diskID)
+	if f.GetDiskFunc != nil {
+		return f.GetDiskFunc(ctx, diskID, opts...)
+	}
+	return blockstorage.Disk{}, nil
+}
+
+func (f *FakeBlockStorage) DeleteDisk(ctx context.Context, diskID uuid.UUID, opts ...api.CallOption) error {
+	f.record("DeleteDisk", opts, diskID)
+	if f.DeleteDiskFunc != nil {
+		return f.DeleteDiskFunc(ctx, diskID, opts...)
+	}
+	return nil
+}
+
+func (f *FakeBlockStorage) AttachDiskToVM(ctx context.Context, diskID, vmID uuid.UUID, opts ...api.CallOption) error {
+	f.record("AttachDiskToVM", opts, diskID, vmID)
+	if f.AttachDiskToVMFunc != nil {
+		return f.AttachDiskToVMFunc(ctx, diskID, vmID, opts...)
+	}
+	return nil
+}
+
+func (f *FakeBlockStorage) DetachDiskFromVM(ctx context.Context, diskID, vmID uuid.UUID, opts ...api.CallOption) error {
+	f.record("DetachDiskFromVM", opts, diskID, vmID)
+	if f.DetachDiskFromVMFunc != nil {
+		return f.DetachDiskFromVMFunc(ctx, diskID, vmID, opts...)
+	}
+	return nil
+}
+
+func (f *FakeBlockStorage) UpdateDiskBillingAccount(ctx context.Context, diskID uuid.UUID, billingAccountID int, opts ...api.CallOption) error {
+	f.record("UpdateDiskBillingAccount", opts, diskID, billingAccountID)
+	if f.UpdateDiskBillingAccountFunc != nil {
+		return f.UpdateDiskBillingAccountFunc(ctx, diskID, billingAccountID, opts...)
+	}
+	return nil
+}
+
+// FakeObjectStorage is configurable objectstorage.Service, methods without a func set return zero values.
+type FakeObjectStorage struct {
+	CallRecorder
+	GetS3ApiURLFunc                func(ctx context.Context, opts ...api.CallOption) (map[string]string, error)
+	GetS3UserInfoFunc              func(ctx context.Context, opts ...api.CallOption) (objectstorage.S3UserInfo, error)
+	GetS3UserKeysFunc              func(ctx context.Context, opts ...api.CallOption) ([]objectstorage.S3Credential, error)
+	GenerateS3UserKeyFunc          func(ctx context.Context, opts ...api.CallOption) ([]objectstorage.S3Credential, error)
+	DeleteS3UserKeyFunc            func(ctx context.Context, accessKey string, opts ...api.CallOption) error
+	ListBucketsFunc                func(ctx context.Context, opts ...api.CallOption) ([]objectstorage.S3Bucket, error)
+	GetBucketFunc                  func(ctx context.Context, bucketName string, opts ...api.CallOption) (objectstorage.S3Bucket, error)
+	CreateBucketFunc               func(ctx context.Context, bucketName string, opts ...api.CallOption) (objectstorage.S3Bucket, error)
+	EnsureBucketFunc               func(ctx context.Context, bucketName string, opts ...api.CallOption) (objectstorage.S3Bucket, bool, error)
+	DeleteBucketFunc               func(ctx context.Context, bucketName string, opts ...api.CallOption) error
+	UpdateBucketBillingAccountFunc func(ctx context.Context, bucketName string, billingAccountID int, opts ...api.CallOption) error
+}
+
+var _ objectstorage.Service = (*FakeObjectStorage)(nil)
+
+func (f *FakeObjectStorage) GetS3ApiURL(ctx context.Context, opts ...api.CallOption) (map[string]string, error) {
+	f.record("GetS3ApiURL", opts)
+	if f.GetS3ApiURLFunc != nil {
+		return f.GetS3ApiURLFunc(ctx, opts...)
+	}
+	return nil, nil
+}
+
+func (f *FakeObjectStorage) GetS3UserInfo(ctx context.Context, opts ...api.CallOption) (objectstorage.S3UserInfo, error) {
+	f.record("GetS3UserInfo", opts)
+	if f.GetS3UserInfoFunc != nil {
+		return f.GetS3UserInfoFunc(ctx, opts...)
+	}
+	return objectstorage.S3UserInfo{}, nil
+}
+
+func (f *FakeObjectStorage) GetS3UserKeys(ctx context.Context, opts ...api.CallOption) ([]objectstorage.S3Credential, error) {
+	f.record("GetS3UserKeys", opts)
+	if f.GetS3UserKeysFunc != nil {
+		return f.GetS3UserKeysFunc(ctx, opts...)
+	}
+	return nil, nil
+}
+
+func (f *FakeObjectStorage) GenerateS3UserKey(ctx context.Context, opts ...api.CallOption) ([]objectstorage.S3Credential, error) {
+	f.record("GenerateS3UserKey", opts)
+	if f.GenerateS3UserKeyFunc != nil {
+		return f.GenerateS3UserKeyFunc(ctx, opts...)
+	}
+	return nil, nil
+}
+
+func (f *FakeObjectStorage) DeleteS3UserKey(ctx context.Context, accessKey string, opts ...api.CallOption) error {
+	f.record("DeleteS3UserKey", opts, accessKey)
+	if f.DeleteS3UserKeyFunc != nil {
+		return f.DeleteS3UserKeyFunc(ctx, accessKey, opts...)
+	}
+	return nil
+}
+
+func (f *FakeObjectStorage) ListBuckets(ctx context.Context, opts ...api.CallOption) ([]objectstorage.S3Bucket, error) {
+	f.record("ListBuckets", opts)
+	if f.ListBucketsFunc != nil {
+		return f.ListBucketsFunc(ctx, opts...)
+	}
+	return nil, nil
+}
+
+func (f *FakeObjectStorage) GetBucket(ctx context.Context, bucketName string, opts ...api.CallOption) (objectstorage.S3Bucket, error) {
+	f.record("GetBucket", opts, bucketName)
+	if f.GetBucketFunc != nil {
+		return f.GetBucketFunc(ctx, bucketName, opts...)
+	}
+	return objectstorage.S3Bucket{}, nil
+}
+
+func (f *FakeObjectStorage) CreateBucket(ctx context.Context, bucketName string, opts ...api.CallOption) (objectstorage.S3Bucket, error) {
+	f.record("CreateBucket", opts, bucketName)
+	if f.CreateBucketFunc != nil {
+		return f.CreateBucketFunc(ctx, bucketName, opts...)
+	}
+	return objectstorage.S3Bucket{}, nil
+}
+
+func (f *FakeObjectStorage) EnsureBucket(ctx context.Context, bucketName string, opts ...api.CallOption) (objectstorage.S3Bucket, bool, error) {
+	f.record("EnsureBucket", opts, bucketName)
+	if f.EnsureBucketFunc != nil {
+		return f.EnsureBucketFunc(ctx, bucketName, opts...)
+	}
+	return objectstorage.S3Bucket{}, false, nil
+}
+
+func (f *FakeObjectStorage) DeleteBucket(ctx context.Context, bucketName string, opts ...api.CallOption) error {
+	f.record("DeleteBucket", opts, bucketName)
+	if f.DeleteBucketFunc != nil {
+		return f.DeleteBucketFunc(ctx, bucketName, opts...)
+	}
+	return nil
+}
+
+func (f *FakeObjectStorage) UpdateBucketBillingAccount(ctx context.Context, bucketName string, billingAccountID int, opts ...api.CallOption) error {
+	f.record("UpdateBucketBillingAccount", opts, bucketName, billingAccountID)
+	if f.UpdateBucketBillingAccountFunc != nil {
+		return f.UpdateBucketBillingAccountFunc(ctx, bucketName, billingAccountID, opts...)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package warrentest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ekaputra07/warren-go"
+	"github.com/ekaputra07/warren-go/api"
+	"github.com/ekaputra07/warren-go/vpc"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeVPC(t *testing.T) {
+	id := uuid.New()
+	errBoom := errors.New("boom")
+	f := &FakeVPC{
+		GetNetworkFunc: func(ctx context.Context, got uuid.UUID, opts ...api.CallOption) (vpc.NetworkInfo, error) {
+			return vpc.NetworkInfo{UUID: got, Name: "backend"}, nil
+		},
+		DeleteNetworkFunc: func(ctx context.Context, id uuid.UUID, opts ...api.CallOption) error {
+			return errBoom
+		},
+	}
+	w := &warren.Warren{VPC: f}
+
+	n, err := w.VPC.GetNetwork(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "backend", n.Name)
+
+	assert.ErrorIs(t, w.VPC.DeleteNetwork(ctx, id), errBoom)
+
+	// not configured, returns zero values
+	nets, err := w.VPC.ListNetworks(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, nets)
+
+	assert.NoError(t, w.VPC.RenameNetwork(ctx, id, "frontend"))
+
+	assert.Equal(t, []Call{
+		{Method: "GetNetwork", Args: []any{id}},
+		{Method: "DeleteNetwork", Args: []any{id}},
+		{Method: "ListNetworks"},
+		{Method: "RenameNetwork", Args: []any{id, "frontend"}},
+	}, f.Calls())
+	assert.Equal(t, []Call{{Method: "DeleteNetwork", Args: []any{id}}}, f.CallsTo("DeleteNetwork"))
+
+	f.Reset()
+	assert.Empty(t, f.Calls())
+}
+
+func TestFakeObjectStorage(t *testing.T) {
+	f := &FakeObjectStorage{}
+	w := &warren.Warren{ObjectStorage: f}
+
+	_, err := w.ObjectStorage.CreateBucket(ctx, "assets")
+	assert.NoError(t, err)
+	assert.NoError(t, w.ObjectStorage.UpdateBucketBillingAccount(ctx, "assets", 2))
+	_, err = w.ObjectStorage.ListBuckets(ctx, api.ForBillingAccount(3))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []Call{
+		{Method: "CreateBucket", Args: []any{"assets"}},
+		{Method: "UpdateBucketBillingAccount", Args: []any{"assets", 2}},
+		{Method: "ListBuckets", Scope: api.Scope{BillingAccountID: 3}},
+	}, f.Calls())
+}
@@ -0,0 +1,162 @@
+package warrentest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/ekaputra07/warren-go/ip"
+	"github.com/google/uuid"
+)
+
+// floatingIP is ip.IPAddressInfo with the location it belongs to
+type floatingIP struct {
+	ip.IPAddressInfo
+	location string
+}
+
+// findIP returns floating IP with given address in given location, writes 404 response if not found.
+func (s *Server) findIP(w http.ResponseWriter, loc, address string) (*floatingIP, bool) {
+	f, ok := s.ips[address]
+	if !ok || f.location != loc {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("floating IP %s not found", address))
+		return nil, false
+	}
+	return f, true
+}
+
+func (s *Server) listIPs(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	ips := []ip.IPAddressInfo{}
+	for _, f := range s.ips {
+		if f.location == p["loc"] {
+			ips = append(ips, f.IPAddressInfo)
+		}
+	}
+	sort.Slice(ips, func(i, j int) bool { return ips[i].ID < ips[j].ID })
+	writeJSON(w, http.StatusOK, ips)
+}
+
+func (s *Server) createIP(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	var payload struct {
+		Name             string `json:"name"`
+		BillingAccountID int    `json:"billing_account_id"`
+	}
+	if !decodeJSON(w, r, &payload) {
+		return
+	}
+	if payload.BillingAccountID == 0 {
+		writeError(w, http.StatusBadRequest, "billing_account_id is required")
+		return
+	}
+	f := s.newIP(p["loc"], payload.Name, payload.BillingAccountID)
+	writeJSON(w, http.StatusOK, f.IPAddressInfo)
+}
+
+// newIP creates and stores a new unassigned floating IP
+func (s *Server) newIP(loc, name string, billingAccountID int) *floatingIP {
+	id := s.id()
+	f := &floatingIP{
+		location: loc,
+		IPAddressInfo: ip.IPAddressInfo{
+			ID:               id,
+			Address:          fmt.Sprintf("203.0.%d.%d", id/256%256, id%256),
+			UserID:           UserID,
+			BillingAccountID: billingAccountID,
+			Type:             "public",
+			Name:             name,
+			Enabled:          true,
+			CreatedAt:        now(),
+			UpdatedAt:        now(),
+			IsVirtual:        true,
+		},
+	}
+	s.ips[f.Address] = f
+	return f
+}
+
+func (s *Server) getIP(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	if f, ok := s.findIP(w, p["loc"], p["address"]); ok {
+		writeJSON(w, http.StatusOK, f.IPAddressInfo)
+	}
+}
+
+func (s *Server) updateIP(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	f, ok := s.findIP(w, p["loc"], p["address"])
+	if !ok {
+		return
+	}
+	var payload struct {
+		Name             string `json:"name"`
+		BillingAccountID int    `json:"billing_account_id"`
+	}
+	if !decodeJSON(w, r, &payload) {
+		return
+	}
+	if payload.BillingAccountID == 0 {
+		writeError(w, http.StatusBadRequest, "billing_account_id is required")
+		return
+	}
+	f.Name = payload.Name
+	f.BillingAccountID = payload.BillingAccountID
+	f.UpdatedAt = now()
+	writeJSON(w, http.StatusOK, f.IPAddressInfo)
+}
+
+func (s *Server) deleteIP(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	f, ok := s.findIP(w, p["loc"], p["address"])
+	if !ok {
+		return
+	}
+	if f.AssignedTo.Valid {
+		writeError(w, http.StatusConflict, "floating IP is assigned, unassign it first")
+		return
+	}
+	delete(s.ips, f.Address)
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+func (s *Server) assignIP(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	f, ok := s.findIP(w, p["loc"], p["address"])
+	if !ok {
+		return
+	}
+	var payload struct {
+		VMUUID uuid.UUID `json:"vm_uuid"`
+	}
+	if !decodeJSON(w, r, &payload) {
+		return
+	}
+	if f.AssignedTo.Valid {
+		writeError(w, http.StatusConflict, fmt.Sprintf("floating IP is already assigned to %s", f.AssignedTo.UUID))
+		return
+	}
+	f.AssignedTo = uuid.NullUUID{UUID: payload.VMUUID, Valid: true}
+	f.AssignedToResourceType = "virtual_machine"
+	if v, ok := s.vms[payload.VMUUID]; ok {
+		f.AssignedToPrivateIP = v.PrivateIPv4
+	}
+	f.UpdatedAt = now()
+	writeJSON(w, http.StatusOK, f.IPAddressInfo)
+}
+
+func (s *Server) unassignIP(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	f, ok := s.findIP(w, p["loc"], p["address"])
+	if !ok {
+		return
+	}
+	var payload struct {
+		VMUUID uuid.UUID `json:"vm_uuid"`
+	}
+	if !decodeJSON(w, r, &payload) {
+		return
+	}
+	if !f.AssignedTo.Valid || f.AssignedTo.UUID != payload.VMUUID {
+		writeError(w, http.StatusConflict, fmt.Sprintf("floating IP is not assigned to %s", payload.VMUUID))
+		return
+	}
+	f.AssignedTo = uuid.NullUUID{}
+	f.AssignedToResourceType = ""
+	f.AssignedToPrivateIP = ""
+	f.UpdatedAt = now()
+	writeJSON(w, http.StatusOK, f.IPAddressInfo)
+}
@@ -0,0 +1,145 @@
+package warrentest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/ekaputra07/warren-go/objectstorage"
+	"github.com/google/uuid"
+)
+
+// S3URL is S3 API URL returned by the server
+const S3URL = "https://s3.warrentest.local"
+
+// s3UserID is the S3 user ID
+const s3UserID = "warrentest"
+
+func (s *Server) getS3ApiURL(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	writeJSON(w, http.StatusOK, map[string]string{"url": S3URL})
+}
+
+func (s *Server) getS3User(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"displayName":   "Warren Test",
+		"email":         "test@warrentest.local",
+		"maxBuckets":    1000,
+		"s3Credentials": s.s3Keys(),
+		"suspended":     0,
+		"userId":        s3UserID,
+	})
+}
+
+// s3Keys returns copy of S3 keys, never nil
+func (s *Server) s3Keys() []objectstorage.S3Credential {
+	return append([]objectstorage.S3Credential{}, s.keys...)
+}
+
+func (s *Server) listS3Keys(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	writeJSON(w, http.StatusOK, s.s3Keys())
+}
+
+func (s *Server) generateS3Key(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	id := s.id()
+	s.keys = append(s.keys, objectstorage.S3Credential{
+		AccessKey: fmt.Sprintf("AK%018d", id),
+		SecretKey: uuid.NewString(),
+		UserID:    s3UserID,
+	})
+	writeJSON(w, http.StatusOK, s.s3Keys())
+}
+
+func (s *Server) deleteS3Key(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	accessKey := r.URL.Query().Get("access_key")
+	for i, k := range s.keys {
+		if k.AccessKey == accessKey {
+			s.keys = append(s.keys[:i], s.keys[i+1:]...)
+			writeJSON(w, http.StatusOK, s.s3Keys())
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, fmt.Sprintf("access key %s not found", accessKey))
+}
+
+// findBucket returns bucket with given name, writes 404 response if not found.
+func (s *Server) findBucket(w http.ResponseWriter, name string) (*objectstorage.S3Bucket, bool) {
+	b, ok := s.buckets[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("bucket %s not found", name))
+		return nil, false
+	}
+	return b, true
+}
+
+func (s *Server) listBuckets(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	billingAccountID, _ := strconv.Atoi(r.URL.Query().Get("billing_account_id"))
+	buckets := []objectstorage.S3Bucket{}
+	for _, b := range s.buckets {
+		if billingAccountID == 0 || b.BillingAccountID == billingAccountID {
+			buckets = append(buckets, *b)
+		}
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Name < buckets[j].Name })
+	writeJSON(w, http.StatusOK, buckets)
+}
+
+func (s *Server) getBucket(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	if b, ok := s.findBucket(w, r.URL.Query().Get("name")); ok {
+		writeJSON(w, http.StatusOK, b)
+	}
+}
+
+func (s *Server) createBucket(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	_ = r.ParseForm()
+	name := r.Form.Get("name")
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	if _, ok := s.buckets[name]; ok {
+		writeError(w, http.StatusConflict, fmt.Sprintf("bucket %s already exists", name))
+		return
+	}
+	billingAccountID, _ := strconv.Atoi(r.Form.Get("billing_account_id"))
+	if billingAccountID == 0 {
+		billingAccountID = BillingAccountID
+	}
+	b := &objectstorage.S3Bucket{
+		Name:             name,
+		BillingAccountID: billingAccountID,
+		CreatedAt:        now(),
+		ModifiedAt:       now(),
+	}
+	s.buckets[name] = b
+	writeJSON(w, http.StatusOK, b)
+}
+
+func (s *Server) updateBucket(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	_ = r.ParseForm()
+	b, ok := s.findBucket(w, r.Form.Get("name"))
+	if !ok {
+		return
+	}
+	billingAccountID, err := strconv.Atoi(r.Form.Get("billing_account_id"))
+	if err != nil || billingAccountID <= 0 {
+		writeError(w, http.StatusBadRequest, "billing_account_id is invalid")
+		return
+	}
+	b.BillingAccountID = billingAccountID
+	b.ModifiedAt = now()
+	writeJSON(w, http.StatusOK, b)
+}
+
+func (s *Server) deleteBucket(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	b, ok := s.findBucket(w, r.URL.Query().Get("name"))
+	if !ok {
+		return
+	}
+	if b.NumObjects > 0 {
+		writeError(w, http.StatusConflict, "bucket is not empty")
+		return
+	}
+	delete(s.buckets, b.Name)
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
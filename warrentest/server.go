@@ -0,0 +1,263 @@
+// Package warrentest provides an in-memory Warren API emulator for testing code built on top of this library.
+//
+// The server implements every endpoint called by the library, keeps consistent state between calls
+// (e.g. created network shows up in the list, deleted disk is gone) and enforces constraints of the
+// real API such as refusing to delete the default network or an assigned floating IP.
+//
+//	s := warrentest.NewServer()
+//	defer s.Close()
+//	w := warren.Init(s.API(), "jkt01")
+//
+// For unit tests that don't need HTTP at all, the package also provides fakes of every
+// service interface (FakeVPC, FakeIP, FakeVM, FakeBlockStorage, FakeObjectStorage, FakeLocation)
+// that record their calls and return whatever their func fields return.
+package warrentest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ekaputra07/warren-go/api"
+	"github.com/ekaputra07/warren-go/location"
+	"github.com/ekaputra07/warren-go/objectstorage"
+	"github.com/ekaputra07/warren-go/vm"
+	"github.com/ekaputra07/warren-go/vpc"
+	"github.com/google/uuid"
+)
+
+const (
+	// APIKey is the only API key accepted by the server
+	APIKey = "warrentest-secret"
+	// UserID is ID of the user owning all resources
+	UserID = 1
+	// BillingAccountID is the default billing account of the user
+	BillingAccountID = 1
+)
+
+// DefaultLocations are locations available in the server
+var DefaultLocations = []location.Location{
+	{DisplayName: "Jakarta", IsDefault: true, IsPreferred: true, OrderNr: 1, Slug: "jkt01", CountryCode: "ID"},
+	{DisplayName: "Singapore", OrderNr: 2, Slug: "sgp01", CountryCode: "SG"},
+}
+
+// DefaultVMLimits are VM limits of every location, running VM can't be resized
+var DefaultVMLimits = vm.Limits{MinVCPU: 1, MaxVCPU: 16, MinMemoryMB: vm.MinMemoryMB, MaxMemoryMB: 65536}
+
+// Server is in-memory Warren API server
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	locations []location.Location
+	vmLimits  vm.Limits
+	networks  map[uuid.UUID]*network
+	ips       map[string]*floatingIP
+	vms       map[uuid.UUID]*virtualMachine
+	backups   map[uuid.UUID]*backup
+	disks     map[uuid.UUID]*disk
+	buckets   map[string]*objectstorage.S3Bucket
+	keys      []objectstorage.S3Credential
+	nextID    int
+}
+
+// network is vpc.NetworkInfo with the location it belongs to
+type network struct {
+	vpc.NetworkInfo
+	location string
+}
+
+// NewServer starts a new server with DefaultLocations and no resources
+func NewServer() *Server {
+	s := &Server{
+		locations: DefaultLocations,
+		vmLimits:  DefaultVMLimits,
+		networks:  map[uuid.UUID]*network{},
+		ips:       map[string]*floatingIP{},
+		vms:       map[uuid.UUID]*virtualMachine{},
+		backups:   map[uuid.UUID]*backup{},
+		disks:     map[uuid.UUID]*disk{},
+		buckets:   map[string]*objectstorage.S3Bucket{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// API returns API client connected to the server
+func (s *Server) API() *api.API {
+	a := api.New(s.URL, APIKey)
+	a.HTTPClient = s.Client()
+	return a
+}
+
+// route is a single endpoint, pattern segments in braces (e.g. `{loc}`) match any value.
+type route struct {
+	method  string
+	pattern string
+	handler func(w http.ResponseWriter, r *http.Request, params map[string]string)
+}
+
+func (s *Server) routes() []route {
+	return []route{
+		{"GET", "/v1/config/locations", s.listLocations},
+
+		{"GET", "/v1/{loc}/network/networks", s.listNetworks},
+		{"POST", "/v1/{loc}/network/network", s.getOrCreateDefaultNetwork},
+		{"GET", "/v1/{loc}/network/network/{id}", s.getNetwork},
+		{"PATCH", "/v1/{loc}/network/network/{id}", s.renameNetwork},
+		{"DELETE", "/v1/{loc}/network/network/{id}", s.deleteNetwork},
+		{"PUT", "/v1/{loc}/network/network/{id}/default", s.setDefaultNetwork},
+
+		{"GET", "/v1/{loc}/network/ip_addresses", s.listIPs},
+		{"POST", "/v1/{loc}/network/ip_addresses", s.createIP},
+		{"GET", "/v1/{loc}/network/ip_addresses/{address}", s.getIP},
+		{"PATCH", "/v1/{loc}/network/ip_addresses/{address}", s.updateIP},
+		{"DELETE", "/v1/{loc}/network/ip_addresses/{address}", s.deleteIP},
+		{"POST", "/v1/{loc}/network/ip_addresses/{address}/assign", s.assignIP},
+		{"POST", "/v1/{loc}/network/ip_addresses/{address}/unassign", s.unassignIP},
+
+		{"GET", "/v1/{loc}/user-resource/vm/list", s.listVMs},
+		{"GET", "/v1/{loc}/user-resource/vm", s.getVM},
+		{"POST", "/v1/{loc}/user-resource/vm", s.createVM},
+		{"DELETE", "/v1/{loc}/user-resource/vm", s.deleteVM},
+		{"POST", "/v1/{loc}/user-resource/vm/resize", s.resizeVM},
+		{"POST", "/v1/{loc}/user-resource/vm/{action}", s.vmAction},
+		{"GET", "/v1/{loc}/user-resource/vm/backups", s.listVMBackups},
+		{"POST", "/v1/{loc}/user-resource/vm/backups/enable", s.enableVMBackups},
+		{"POST", "/v1/{loc}/user-resource/vm/backups/disable", s.disableVMBackups},
+		{"POST", "/v1/{loc}/user-resource/vm/backups/restore", s.restoreVMBackup},
+		{"GET", "/v1/{loc}/config/vm_limits", s.getVMLimits},
+
+		{"GET", "/v1/storage/disks", s.listDisks},
+		{"POST", "/v1/storage/disks", s.createDisk},
+		{"GET", "/v1/storage/disks/{id}", s.getDisk},
+		{"PATCH", "/v1/storage/disks/{id}", s.updateDisk},
+		{"DELETE", "/v1/storage/disks/{id}", s.deleteDisk},
+		{"POST", "/v1/user-resource/vm/storage/attach", s.attachDisk},
+		{"POST", "/v1/user-resource/vm/storage/detach", s.detachDisk},
+
+		{"GET", "/v1/storage/api/s3", s.getS3ApiURL},
+		{"GET", "/v1/storage/user", s.getS3User},
+		{"GET", "/v1/storage/user/keys", s.listS3Keys},
+		{"POST", "/v1/storage/user/keys", s.generateS3Key},
+		{"DELETE", "/v1/storage/user/keys", s.deleteS3Key},
+		{"GET", "/v1/storage/bucket/list", s.listBuckets},
+		{"GET", "/v1/storage/bucket", s.getBucket},
+		{"PUT", "/v1/storage/bucket", s.createBucket},
+		{"PATCH", "/v1/storage/bucket", s.updateBucket},
+		{"DELETE", "/v1/storage/bucket", s.deleteBucket},
+	}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("apikey") != APIKey {
+		writeError(w, http.StatusUnauthorized, "invalid API key")
+		return
+	}
+
+	pathMatched := false
+	for _, rt := range s.routes() {
+		params, ok := match(rt.pattern, r.URL.Path)
+		if !ok {
+			continue
+		}
+		pathMatched = true
+		if rt.method != r.Method {
+			continue
+		}
+		if loc, ok := params["loc"]; ok && !s.hasLocation(loc) {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("location %s not found", loc))
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		rt.handler(w, r, params)
+		return
+	}
+	if pathMatched {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	writeError(w, http.StatusNotFound, "not found")
+}
+
+// match matches path against pattern and returns values of the pattern params.
+func match(pattern, path string) (map[string]string, bool) {
+	ps := strings.Split(strings.Trim(pattern, "/"), "/")
+	segs := strings.Split(strings.Trim(path, "/"), "/")
+	if len(ps) != len(segs) {
+		return nil, false
+	}
+	params := map[string]string{}
+	for i, p := range ps {
+		if strings.HasPrefix(p, "{") && strings.HasSuffix(p, "}") {
+			if segs[i] == "" {
+				return nil, false
+			}
+			params[p[1:len(p)-1]] = segs[i]
+			continue
+		}
+		if p != segs[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func (s *Server) hasLocation(slug string) bool {
+	for _, l := range s.locations {
+		if l.Slug == slug {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) listLocations(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	writeJSON(w, http.StatusOK, s.locations)
+}
+
+// id returns next sequential ID
+func (s *Server) id() int {
+	s.nextID++
+	return s.nextID
+}
+
+// now returns current time in the format used by Warren
+func now() string {
+	return time.Now().UTC().Format("2006-01-02 15:04:05")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]any{"message": msg, "code": status})
+}
+
+// decodeJSON decodes json request body into v, writes 400 response on failure.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json payload")
+		return false
+	}
+	return true
+}
+
+// parseUUID parses id, writes 404 response on failure as the resource can't exist.
+func parseUUID(w http.ResponseWriter, id string) (uuid.UUID, bool) {
+	u, err := uuid.Parse(id)
+	if err != nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("%s not found", id))
+		return uuid.Nil, false
+	}
+	return u, true
+}
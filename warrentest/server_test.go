@@ -0,0 +1,504 @@
+package warrentest
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ekaputra07/warren-go/api"
+	"github.com/ekaputra07/warren-go/blockstorage"
+	"github.com/ekaputra07/warren-go/faultinject"
+	"github.com/ekaputra07/warren-go/ip"
+	"github.com/ekaputra07/warren-go/location"
+	"github.com/ekaputra07/warren-go/objectstorage"
+	"github.com/ekaputra07/warren-go/vm"
+	"github.com/ekaputra07/warren-go/vpc"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.Background()
+
+func TestServer_Unauthorized(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	a := s.API()
+	a.APIKey = "wrong"
+	_, err := location.NewClient(a).ListLocations(ctx)
+	assert.True(t, api.IsUnauthorized(err))
+}
+
+func TestServer_Locations(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	locations, err := location.NewClient(s.API()).ListLocations(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultLocations, locations)
+
+	// unknown location
+	_, err = vpc.NewClient(s.API(), "xxx01").ListNetworks(ctx)
+	assert.True(t, api.IsNotFound(err))
+}
+
+func TestServer_VPC(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	c := vpc.NewClient(s.API(), "jkt01")
+
+	def, err := c.GetOrCreateDefaultNetwork(ctx, "Default")
+	assert.NoError(t, err)
+	assert.True(t, def.IsDefault)
+
+	// same default network is returned
+	again, err := c.GetOrCreateDefaultNetwork(ctx, "Other")
+	assert.NoError(t, err)
+	assert.Equal(t, def.UUID, again.UUID)
+
+	other := s.AddNetwork("jkt01", "Other")
+	nets, err := c.ListNetworks(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, nets, 2)
+
+	// other location is separate
+	nets, err = vpc.NewClient(s.API(), "sgp01").ListNetworks(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, nets)
+
+	assert.NoError(t, c.RenameNetwork(ctx, other.UUID, "Renamed"))
+	n, err := c.GetNetwork(ctx, other.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Renamed", n.Name)
+
+	// default network can't be deleted
+	assert.True(t, api.IsConflict(c.DeleteNetwork(ctx, def.UUID)))
+
+	assert.NoError(t, c.SetDefaultNetwork(ctx, other.UUID))
+	assert.NoError(t, c.DeleteNetwork(ctx, def.UUID))
+
+	_, err = c.GetNetwork(ctx, def.UUID)
+	assert.True(t, api.IsNotFound(err))
+}
+
+func TestServer_IP(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	c := ip.NewClient(s.API(), "jkt01")
+	vmID := uuid.New()
+
+	info := ip.IPAddressInfo{Name: "web", BillingAccountID: BillingAccountID}
+	assert.NoError(t, c.CreateFloatingIP(ctx, &info))
+	assert.NotEmpty(t, info.Address)
+
+	ips, err := c.ListFloatingIPs(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, ips, 1)
+
+	info.Name = "api"
+	assert.NoError(t, c.UpdateFloatingIP(ctx, info))
+
+	assert.NoError(t, c.AssignFloatingIPToVM(ctx, info.Address, vmID))
+	assert.True(t, api.IsConflict(c.AssignFloatingIPToVM(ctx, info.Address, uuid.New())))
+
+	got, err := c.GetFloatingIP(ctx, info.Address)
+	assert.NoError(t, err)
+	assert.Equal(t, "api", got.Name)
+	assert.Equal(t, uuid.NullUUID{UUID: vmID, Valid: true}, got.AssignedTo)
+
+	// assigned IP can't be deleted
+	assert.True(t, api.IsConflict(c.DeleteFloatingIP(ctx, info.Address)))
+
+	assert.True(t, api.IsConflict(c.UnassignFloatingIPFromVM(ctx, info.Address, uuid.New())))
+	assert.NoError(t, c.UnassignFloatingIPFromVM(ctx, info.Address, vmID))
+	assert.NoError(t, c.DeleteFloatingIP(ctx, info.Address))
+
+	_, err = c.GetFloatingIP(ctx, info.Address)
+	assert.True(t, api.IsNotFound(err))
+}
+
+func TestServer_VM(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	a := s.API()
+	c := vm.NewClient(a, "jkt01")
+
+	web := s.AddVM("jkt01", "web")
+	s.AddVM("sgp01", "other")
+
+	vms, err := c.ListVMs(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, vms, 1)
+	assert.Equal(t, "web", vms[0].Name)
+	assert.Equal(t, vm.StatusRunning, vms[0].Status)
+
+	// VM joins the default network
+	def, err := vpc.NewClient(a, "jkt01").GetOrCreateDefaultNetwork(ctx, "Default")
+	assert.NoError(t, err)
+	assert.Equal(t, def.UUID, web.NetworkUUID)
+	assert.Equal(t, uuid.UUIDs{web.UUID}, def.VMUUIDs)
+
+	// floating IP and attached disk show up on the VM
+	info := ip.IPAddressInfo{Name: "web", BillingAccountID: BillingAccountID}
+	assert.NoError(t, ip.NewClient(a, "jkt01").CreateFloatingIP(ctx, &info))
+	assert.NoError(t, ip.NewClient(a, "jkt01").AssignFloatingIPToVM(ctx, info.Address, web.UUID))
+	diskID := s.AddDisk(50)
+	assert.NoError(t, blockstorage.NewClient(a).AttachDiskToVM(ctx, diskID, web.UUID))
+
+	got, err := c.GetVM(ctx, web.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, info.Address, got.PublicIPv4)
+	assert.Len(t, got.Storage, 2)
+	assert.True(t, got.Storage[0].Primary)
+	assert.Equal(t, diskID, got.Storage[1].UUID)
+
+	// VM in another location
+	_, err = c.GetVM(ctx, web.UUID, api.InLocation("sgp01"))
+	assert.True(t, api.IsNotFound(err))
+}
+
+func TestServer_CreateVM(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	a := s.API()
+	c := vm.NewClient(a, "jkt01")
+	backend := s.AddNetwork("jkt01", "Backend")
+
+	spec := vm.CreateVMSpec{
+		Name:            "web",
+		OSName:          "ubuntu",
+		OSVersion:       "22.04",
+		VCPU:            2,
+		MemoryMB:        2048,
+		DiskSizeGB:      40,
+		NetworkUUID:     backend.UUID,
+		Username:        "admin",
+		Password:        "s3cret!",
+		ReservePublicIP: true,
+	}
+	v, err := c.CreateVM(ctx, spec)
+	assert.NoError(t, err)
+	assert.Equal(t, vm.StatusRunning, v.Status)
+	assert.Equal(t, backend.UUID, v.NetworkUUID)
+	assert.Equal(t, 40, v.Storage[0].SizeGB)
+	assert.NotEmpty(t, v.PublicIPv4)
+
+	// reserved public IP is a floating IP assigned to the VM
+	f, err := ip.NewClient(a, "jkt01").GetFloatingIP(ctx, v.PublicIPv4)
+	assert.NoError(t, err)
+	assert.Equal(t, uuid.NullUUID{UUID: v.UUID, Valid: true}, f.AssignedTo)
+
+	// VM keeps its network
+	assert.True(t, api.IsConflict(vpc.NewClient(a, "jkt01").DeleteNetwork(ctx, backend.UUID)))
+
+	// unknown network and source disk
+	spec.NetworkUUID = uuid.New()
+	_, err = c.CreateVM(ctx, spec)
+	assert.True(t, api.IsNotFound(err))
+
+	spec.NetworkUUID = uuid.Nil
+	spec.SourceDisk = uuid.New()
+	_, err = c.CreateVM(ctx, spec)
+	assert.True(t, api.IsNotFound(err))
+}
+
+func TestServer_VMPower(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	a := s.API()
+	c := vm.NewClient(a, "jkt01").WithWaitForStatus(time.Millisecond)
+	web := s.AddVM("jkt01", "web")
+
+	_, err := c.Start(ctx, web.UUID)
+	assert.ErrorIs(t, err, vm.ErrAlreadyInState)
+
+	v, err := c.Stop(ctx, web.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, vm.StatusStopped, v.Status)
+
+	_, err = c.Reboot(ctx, web.UUID)
+	assert.ErrorIs(t, err, vm.ErrInvalidState)
+
+	v, err = c.Start(ctx, web.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, vm.StatusRunning, v.Status)
+
+	v, err = c.ForceStop(ctx, web.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, vm.StatusStopped, v.Status)
+
+	// deleted VM leaves its network
+	assert.NoError(t, c.Delete(ctx, web.UUID))
+	_, err = c.GetVM(ctx, web.UUID)
+	assert.True(t, api.IsNotFound(err))
+	def, err := vpc.NewClient(a, "jkt01").GetOrCreateDefaultNetwork(ctx, "Default")
+	assert.NoError(t, err)
+	assert.Empty(t, def.VMUUIDs)
+}
+
+func TestServer_VMResize(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	c := vm.NewClient(s.API(), "jkt01")
+	web := s.AddVM("jkt01", "web")
+
+	res, err := c.Resize(ctx, web.UUID, 2, 2048)
+	assert.NoError(t, err)
+	assert.True(t, res.Restarted)
+	assert.Equal(t, vm.Size{VCPU: 1, MemoryMB: 1024}, res.Old)
+	assert.Equal(t, vm.StatusRunning, res.VM.Status)
+	assert.Equal(t, 2048, res.VM.MemoryMB)
+
+	_, err = c.Resize(ctx, web.UUID, 32, 2048)
+	assert.ErrorIs(t, err, vm.ErrInvalidSpec)
+
+	// online resize
+	l := DefaultVMLimits
+	l.OnlineResize = true
+	s.SetVMLimits(l)
+	res, err = c.Resize(ctx, web.UUID, 4, 4096)
+	assert.NoError(t, err)
+	assert.False(t, res.Restarted)
+	assert.Equal(t, 4, res.VM.VCPU)
+}
+
+func TestServer_VMBackups(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	c := vm.NewClient(s.API(), "jkt01")
+	web := s.AddVM("jkt01", "web")
+
+	v, err := c.EnableBackups(ctx, web.UUID)
+	assert.NoError(t, err)
+	assert.True(t, v.Backup)
+
+	first := s.AddBackup(web.UUID)
+	second := s.AddBackup(web.UUID)
+	backups, err := c.ListBackups(ctx, web.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, []vm.Backup{first, second}, backups)
+	assert.Equal(t, vm.BackupStatusAvailable, first.Status)
+	assert.Equal(t, 20, first.SizeGB)
+
+	_, err = c.RestoreBackup(ctx, web.UUID, first.UUID)
+	assert.NoError(t, err)
+	_, err = c.RestoreBackup(ctx, web.UUID, uuid.New())
+	assert.True(t, api.IsNotFound(err))
+
+	// new VM from the backup
+	clone, err := c.CreateVM(ctx, vm.CreateVMSpec{
+		Name:         "web-clone",
+		VCPU:         1,
+		MemoryMB:     1024,
+		DiskSizeGB:   20,
+		Username:     "admin",
+		Password:     "s3cret!",
+		SourceBackup: second.UUID,
+	})
+	assert.NoError(t, err)
+	assert.NotEqual(t, web.UUID, clone.UUID)
+
+	v, err = c.DisableBackups(ctx, web.UUID)
+	assert.NoError(t, err)
+	assert.False(t, v.Backup)
+}
+
+func TestServer_BlockStorage(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	c := blockstorage.NewClient(s.API())
+	vmID := uuid.New()
+
+	d := blockstorage.Disk{SizeGB: 20, SourceImageType: blockstorage.ImageTypeOSBase, SourceImage: "ubuntu_20.04"}
+	assert.NoError(t, c.CreateDisk(ctx, &d))
+	assert.NotEqual(t, uuid.Nil, d.UUID)
+	assert.Equal(t, BillingAccountID, d.BillingAccountID)
+	assert.Equal(t, DiskStatusDetached, d.Status)
+
+	disks, err := c.ListDisks(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, disks, 1)
+	assert.Equal(t, d, disks[0])
+
+	assert.NoError(t, c.AttachDiskToVM(ctx, d.UUID, vmID))
+	assert.True(t, api.IsConflict(c.AttachDiskToVM(ctx, d.UUID, uuid.New())))
+
+	got, err := c.GetDisk(ctx, d.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, DiskStatusAttached, got.Status)
+
+	// attached disk can't be deleted
+	assert.True(t, api.IsConflict(c.DeleteDisk(ctx, d.UUID)))
+
+	assert.NoError(t, c.DetachDiskFromVM(ctx, d.UUID, vmID))
+	assert.NoError(t, c.UpdateDiskBillingAccount(ctx, d.UUID, 2))
+	assert.NoError(t, c.DeleteDisk(ctx, d.UUID))
+
+	_, err = c.GetDisk(ctx, d.UUID)
+	assert.True(t, api.IsNotFound(err))
+}
+
+func TestServer_ObjectStorage(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	c := objectstorage.NewClient(s.API())
+
+	u, err := c.GetS3ApiURL(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, S3URL, u["url"])
+
+	keys, err := c.GenerateS3UserKey(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 1)
+
+	info, err := c.GetS3UserInfo(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, keys, info.S3Credentials)
+
+	assert.NoError(t, c.DeleteS3UserKey(ctx, keys[0].AccessKey))
+	keys, err = c.GetS3UserKeys(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+
+	_, err = c.CreateBucket(ctx, "a")
+	assert.NoError(t, err)
+	_, err = c.CreateBucket(ctx, "a")
+	assert.True(t, api.IsConflict(err))
+	_, err = c.CreateBucket(ctx, "b", api.ForBillingAccount(2))
+	assert.NoError(t, err)
+
+	c = c.WithBillingAccount(2)
+	buckets, err := c.ListBuckets(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, buckets, 1)
+
+	assert.NoError(t, c.UpdateBucketBillingAccount(ctx, "a", 2))
+	buckets, err = c.ListBuckets(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, buckets, 2)
+
+	assert.NoError(t, c.DeleteBucket(ctx, "a"))
+	_, err = c.GetBucket(ctx, "a")
+	assert.True(t, api.IsNotFound(err))
+}
+
+func TestServer_MethodNotAllowed(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	err := api.DoNoContent(ctx, s.API(), api.RequestConfig{Method: "DELETE", Path: "/v1/config/locations"})
+	assert.True(t, api.HasStatus(err, http.StatusMethodNotAllowed))
+}
+
+func TestServer_CreateDisk(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	rc := api.RequestConfig{
+		Method: "POST",
+		Path:   "/v1/storage/disks",
+		Data:   url.Values{"size_gb": {"20"}, "source_image_type": {"OS_BASE"}, "source_image": {"ubuntu_20.04"}},
+	}
+	d, err := api.Do[map[string]any](ctx, s.API(), rc)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(20), d["size_gb"])
+	assert.Equal(t, float64(BillingAccountID), d["billing_account_id"])
+	assert.Equal(t, "ubuntu_20.04", d["source_image"])
+
+	rc.Data = url.Values{"size_gb": {"0"}}
+	_, err = api.Do[map[string]any](ctx, s.API(), rc)
+	assert.True(t, api.HasStatus(err, http.StatusBadRequest))
+}
+
+// lostResponseAPI returns API client whose create requests (method and path) are applied by the server
+// but their responses are lost, as if the connection dropped.
+func lostResponseAPI(s *Server, method, path string) *api.API {
+	a := s.API()
+	a.HTTPClient = &http.Client{Transport: faultinject.New(s.Client().Transport,
+		faultinject.Rule{Method: method, Path: path, DropConnection: true},
+	)}
+	return a
+}
+
+func TestServer_EnsureFloatingIP(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	c := ip.NewClient(s.API(), "jkt01")
+
+	info, created, err := c.EnsureFloatingIP(ctx, ip.IPAddressInfo{Name: "web", BillingAccountID: BillingAccountID})
+	assert.NoError(t, err)
+	assert.True(t, created)
+	again, created, err := c.EnsureFloatingIP(ctx, ip.IPAddressInfo{Name: "web", BillingAccountID: BillingAccountID})
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, info, again)
+
+	lost := ip.NewClient(lostResponseAPI(s, "POST", "/v1/*/network/ip_addresses"), "jkt01")
+	info, created, err = lost.EnsureFloatingIP(ctx, ip.IPAddressInfo{Name: "db", BillingAccountID: BillingAccountID})
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "db", info.Name)
+
+	ips, err := c.ListFloatingIPs(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, ips, 2)
+}
+
+func TestServer_EnsureBucket(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	c := objectstorage.NewClient(s.API())
+
+	_, created, err := c.EnsureBucket(ctx, "a")
+	assert.NoError(t, err)
+	assert.True(t, created)
+	_, created, err = c.EnsureBucket(ctx, "a")
+	assert.NoError(t, err)
+	assert.False(t, created)
+
+	lost := objectstorage.NewClient(lostResponseAPI(s, "PUT", "/v1/storage/bucket"))
+	b, created, err := lost.EnsureBucket(ctx, "b")
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "b", b.Name)
+
+	buckets, err := c.ListBuckets(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, buckets, 2)
+}
+
+func TestServer_EnsureDefaultNetwork(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	c := vpc.NewClient(lostResponseAPI(s, "POST", "/v1/*/network/network"), "jkt01")
+
+	n, created, err := c.EnsureDefaultNetwork(ctx, "Default")
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.True(t, n.IsDefault)
+
+	again, created, err := c.EnsureDefaultNetwork(ctx, "Default")
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, n, again)
+}
+
+func TestServer_EnsureDisk(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.AddDisk(20)
+	c := blockstorage.NewClient(lostResponseAPI(s, "POST", "/v1/storage/disks"))
+
+	spec := blockstorage.Disk{SizeGB: 20, SourceImageType: blockstorage.ImageTypeEmpty}
+	d, created, err := c.EnsureDisk(ctx, spec)
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.NotEqual(t, uuid.Nil, d.UUID)
+
+	disks, err := c.ListDisks(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, disks, 2)
+	assert.Equal(t, d, disks[1])
+}
@@ -0,0 +1,419 @@
+package warrentest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/ekaputra07/warren-go/vm"
+	"github.com/google/uuid"
+)
+
+// virtualMachine is vm.VM with the location it belongs to
+type virtualMachine struct {
+	vm.VM
+	location string
+}
+
+// findVM returns VM with given id in given location, writes 404 response if not found.
+func (s *Server) findVM(w http.ResponseWriter, loc, id string) (*virtualMachine, bool) {
+	u, ok := parseUUID(w, id)
+	if !ok {
+		return nil, false
+	}
+	v, ok := s.vms[u]
+	if !ok || v.location != loc {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("VM %s not found", id))
+		return nil, false
+	}
+	return v, true
+}
+
+// render returns the VM as served by the API, with its floating IP and attached disks
+func (s *Server) render(v *virtualMachine) vm.VM {
+	out := v.VM
+	out.Storage = append([]vm.Storage{}, v.Storage...)
+	for _, f := range s.ips {
+		if f.AssignedTo.Valid && f.AssignedTo.UUID == v.UUID {
+			out.PublicIPv4 = f.Address
+		}
+	}
+	disks := []*disk{}
+	for _, d := range s.disks {
+		if d.vm.Valid && d.vm.UUID == v.UUID {
+			disks = append(disks, d)
+		}
+	}
+	sort.Slice(disks, func(i, j int) bool { return disks[i].seq < disks[j].seq })
+	for _, d := range disks {
+		out.Storage = append(out.Storage, vm.Storage{
+			ID:        d.seq,
+			UUID:      d.UUID,
+			Name:      d.UUID.String(),
+			Pool:      "default",
+			Type:      "block",
+			SizeGB:    d.SizeGB,
+			UserID:    d.UserID,
+			CreatedAt: d.CreatedAt,
+			UpdatedAt: d.UpdatedAt,
+		})
+	}
+	return out
+}
+
+func (s *Server) listVMs(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	vms := []vm.VM{}
+	for _, v := range s.vms {
+		if v.location == p["loc"] {
+			vms = append(vms, s.render(v))
+		}
+	}
+	sort.Slice(vms, func(i, j int) bool { return vms[i].ID < vms[j].ID })
+	writeJSON(w, http.StatusOK, vms)
+}
+
+func (s *Server) createVM(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	_ = r.ParseForm()
+	f := r.Form
+	vcpu, _ := strconv.Atoi(f.Get("vcpu"))
+	ram, _ := strconv.Atoi(f.Get("ram"))
+	diskGB, _ := strconv.Atoi(f.Get("disks"))
+	source := f.Get("source_uuid") != "" || f.Get("source_replica") != "" || f.Get("source_backup") != ""
+	switch {
+	case f.Get("name") == "":
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	case !source && (f.Get("os_name") == "" || f.Get("os_version") == ""):
+		writeError(w, http.StatusBadRequest, "os_name and os_version are required")
+		return
+	case vcpu < 1 || ram < vm.MinMemoryMB || diskGB < vm.MinDiskSizeGB:
+		writeError(w, http.StatusBadRequest, "vcpu, ram or disks is invalid")
+		return
+	case f.Get("username") == "" || (f.Get("password") == "" && f.Get("public_key") == ""):
+		writeError(w, http.StatusBadRequest, "username and password or public_key are required")
+		return
+	}
+
+	if id := f.Get("source_uuid"); id != "" {
+		d, ok := s.findDisk(w, id)
+		if !ok {
+			return
+		}
+		if diskGB < d.SizeGB {
+			writeError(w, http.StatusBadRequest, "disks is smaller than the source disk")
+			return
+		}
+	}
+	if id := f.Get("source_replica"); id != "" && !s.hasSnapshot(id) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("snapshot %s not found", id))
+		return
+	}
+	if id := f.Get("source_backup"); id != "" {
+		if _, ok := s.findBackup(w, p["loc"], id); !ok {
+			return
+		}
+	}
+
+	n := s.defaultNetwork(p["loc"])
+	if id := f.Get("network_uuid"); id != "" {
+		var ok bool
+		if n, ok = s.findNetwork(w, p["loc"], id); !ok {
+			return
+		}
+	}
+	billingAccountID, _ := strconv.Atoi(f.Get("billing_account_id"))
+
+	v := s.newVM(p["loc"], n, vm.VM{
+		Name:             f.Get("name"),
+		Status:           vm.StatusRunning,
+		VCPU:             vcpu,
+		MemoryMB:         ram,
+		OSName:           f.Get("os_name"),
+		OSVersion:        f.Get("os_version"),
+		BillingAccountID: billingAccountID,
+		Username:         f.Get("username"),
+	}, diskGB)
+	if f.Get("reserve_public_ip") == "true" {
+		addr := s.newIP(p["loc"], v.Name, v.BillingAccountID)
+		addr.AssignedTo = uuid.NullUUID{UUID: v.UUID, Valid: true}
+		addr.AssignedToResourceType = "virtual_machine"
+		addr.AssignedToPrivateIP = v.PrivateIPv4
+	}
+	writeJSON(w, http.StatusOK, s.render(v))
+}
+
+// hasSnapshot reports whether snapshot with given id exists
+func (s *Server) hasSnapshot(id string) bool {
+	for _, d := range s.disks {
+		for _, snap := range d.Snapshots {
+			if snap.UUID.String() == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *Server) getVM(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	if v, ok := s.findVM(w, p["loc"], r.URL.Query().Get("uuid")); ok {
+		writeJSON(w, http.StatusOK, s.render(v))
+	}
+}
+
+// vmAction performs power action, the VM reaches the target status immediately
+func (s *Server) vmAction(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	_ = r.ParseForm()
+	var target vm.Status
+	switch p["action"] {
+	case "start", "reboot":
+		target = vm.StatusRunning
+	case "stop":
+		target = vm.StatusStopped
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	v, ok := s.findVM(w, p["loc"], r.Form.Get("uuid"))
+	if !ok {
+		return
+	}
+	if v.Status.Transitional() || (p["action"] == "reboot") != (v.Status == target) {
+		writeError(w, http.StatusConflict, fmt.Sprintf("can not %s VM in %s state", p["action"], v.Status))
+		return
+	}
+	v.Status = target
+	v.UpdatedAt = now()
+	writeJSON(w, http.StatusOK, s.render(v))
+}
+
+// deleteVM deletes the VM, its floating IP and disks are released
+func (s *Server) deleteVM(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	v, ok := s.findVM(w, p["loc"], r.URL.Query().Get("uuid"))
+	if !ok {
+		return
+	}
+	if v.Status.Transitional() {
+		writeError(w, http.StatusConflict, fmt.Sprintf("can not delete VM in %s state", v.Status))
+		return
+	}
+	for _, f := range s.ips {
+		if f.AssignedTo.Valid && f.AssignedTo.UUID == v.UUID {
+			f.AssignedTo = uuid.NullUUID{}
+			f.AssignedToResourceType = ""
+			f.AssignedToPrivateIP = ""
+		}
+	}
+	for _, d := range s.disks {
+		if d.vm.Valid && d.vm.UUID == v.UUID {
+			d.vm = uuid.NullUUID{}
+			d.Status = DiskStatusDetached
+		}
+	}
+	if n, ok := s.networks[v.NetworkUUID]; ok {
+		ids := uuid.UUIDs{}
+		for _, id := range n.VMUUIDs {
+			if id != v.UUID {
+				ids = append(ids, id)
+			}
+		}
+		n.VMUUIDs = ids
+		n.ResourceCount = len(ids)
+	}
+	delete(s.vms, v.UUID)
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+func (s *Server) getVMLimits(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	writeJSON(w, http.StatusOK, s.vmLimits)
+}
+
+// SetVMLimits replaces VM limits of every location
+func (s *Server) SetVMLimits(l vm.Limits) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.vmLimits = l
+}
+
+func (s *Server) resizeVM(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	_ = r.ParseForm()
+	v, ok := s.findVM(w, p["loc"], r.Form.Get("uuid"))
+	if !ok {
+		return
+	}
+	vcpu, _ := strconv.Atoi(r.Form.Get("vcpu"))
+	ram, _ := strconv.Atoi(r.Form.Get("ram"))
+	l := s.vmLimits
+	if vcpu < l.MinVCPU || vcpu > l.MaxVCPU || ram < l.MinMemoryMB || ram > l.MaxMemoryMB {
+		writeError(w, http.StatusBadRequest, "vcpu or ram is out of limits")
+		return
+	}
+	if v.Status.Transitional() || (v.Status == vm.StatusRunning && !l.OnlineResize) {
+		writeError(w, http.StatusConflict, fmt.Sprintf("can not resize VM in %s state", v.Status))
+		return
+	}
+	v.VCPU = vcpu
+	v.MemoryMB = ram
+	v.UpdatedAt = now()
+	writeJSON(w, http.StatusOK, s.render(v))
+}
+
+// backup is vm.Backup with the location it belongs to
+type backup struct {
+	vm.Backup
+	location string
+	seq      int
+}
+
+// findBackup returns available backup with given id in given location, writes error response otherwise.
+func (s *Server) findBackup(w http.ResponseWriter, loc, id string) (*backup, bool) {
+	u, ok := parseUUID(w, id)
+	if !ok {
+		return nil, false
+	}
+	b, ok := s.backups[u]
+	if !ok || b.location != loc {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("backup %s not found", id))
+		return nil, false
+	}
+	if b.Status != vm.BackupStatusAvailable {
+		writeError(w, http.StatusConflict, fmt.Sprintf("backup is %s", b.Status))
+		return nil, false
+	}
+	return b, true
+}
+
+func (s *Server) setVMBackups(w http.ResponseWriter, r *http.Request, p map[string]string, enabled bool) {
+	_ = r.ParseForm()
+	v, ok := s.findVM(w, p["loc"], r.Form.Get("uuid"))
+	if !ok {
+		return
+	}
+	v.Backup = enabled
+	v.UpdatedAt = now()
+	writeJSON(w, http.StatusOK, s.render(v))
+}
+
+func (s *Server) enableVMBackups(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	s.setVMBackups(w, r, p, true)
+}
+
+func (s *Server) disableVMBackups(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	s.setVMBackups(w, r, p, false)
+}
+
+func (s *Server) listVMBackups(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	v, ok := s.findVM(w, p["loc"], r.URL.Query().Get("uuid"))
+	if !ok {
+		return
+	}
+	backups := []*backup{}
+	for _, b := range s.backups {
+		if b.VMUUID == v.UUID {
+			backups = append(backups, b)
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].seq < backups[j].seq })
+	writeJSON(w, http.StatusOK, backups)
+}
+
+func (s *Server) restoreVMBackup(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	_ = r.ParseForm()
+	v, ok := s.findVM(w, p["loc"], r.Form.Get("uuid"))
+	if !ok {
+		return
+	}
+	b, ok := s.findBackup(w, p["loc"], r.Form.Get("backup_uuid"))
+	if !ok {
+		return
+	}
+	if b.VMUUID != v.UUID {
+		writeError(w, http.StatusBadRequest, "backup doesn't belong to the VM")
+		return
+	}
+	if v.Status.Transitional() {
+		writeError(w, http.StatusConflict, fmt.Sprintf("can not restore VM in %s state", v.Status))
+		return
+	}
+	v.UpdatedAt = now()
+	writeJSON(w, http.StatusOK, s.render(v))
+}
+
+// AddBackup adds available backup point of the VM, as if taken by the provider. It panics if the VM doesn't exist.
+func (s *Server) AddBackup(vmUUID uuid.UUID) vm.Backup {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.vms[vmUUID]
+	if !ok {
+		panic(fmt.Sprintf("warrentest: VM %s not found", vmUUID))
+	}
+	b := &backup{
+		location: v.location,
+		Backup: vm.Backup{
+			UUID:      uuid.New(),
+			VMUUID:    v.UUID,
+			Status:    vm.BackupStatusAvailable,
+			SizeGB:    v.Storage[0].SizeGB,
+			CreatedAt: now(),
+		},
+		seq: s.id(),
+	}
+	s.backups[b.UUID] = b
+	return b.Backup
+}
+
+// AddVM adds a running VM to the default network of given location (created if there's none),
+// useful to seed the server state.
+func (s *Server) AddVM(loc, name string) vm.VM {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.render(s.newVM(loc, s.defaultNetwork(loc), vm.VM{
+		Name:      name,
+		Status:    vm.StatusRunning,
+		VCPU:      1,
+		MemoryMB:  1024,
+		OSName:    "ubuntu",
+		OSVersion: "22.04",
+	}, 20))
+}
+
+// newVM creates and stores a new VM in network n based on spec with a primary disk of given size
+func (s *Server) newVM(loc string, n *network, spec vm.VM, diskGB int) *virtualMachine {
+	id := s.id()
+	v := &virtualMachine{
+		location: loc,
+		VM:       spec,
+	}
+	v.ID = id
+	v.UUID = uuid.New()
+	v.Hostname = spec.Name
+	v.Storage = []vm.Storage{{
+		ID:        s.id(),
+		UUID:      uuid.New(),
+		Name:      spec.Name + "-boot",
+		Pool:      "default",
+		Type:      "block",
+		Primary:   true,
+		SizeGB:    diskGB,
+		UserID:    UserID,
+		CreatedAt: now(),
+		UpdatedAt: now(),
+	}}
+	v.MAC = fmt.Sprintf("52:54:00:00:%02x:%02x", id/256%256, id%256)
+	v.PrivateIPv4 = fmt.Sprintf("10.%d.0.%d", n.VLANID%256, id%253+2)
+	v.NetworkUUID = n.UUID
+	if v.BillingAccountID == 0 {
+		v.BillingAccountID = BillingAccountID
+	}
+	v.UserID = UserID
+	if v.Username == "" {
+		v.Username = "warrentest"
+	}
+	v.CreatedAt = now()
+	v.UpdatedAt = now()
+
+	n.VMUUIDs = append(n.VMUUIDs, v.UUID)
+	n.ResourceCount = len(n.VMUUIDs)
+	s.vms[v.UUID] = v
+	return v
+}
@@ -0,0 +1,156 @@
+package warrentest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/ekaputra07/warren-go/vpc"
+	"github.com/google/uuid"
+)
+
+// locationNetworks returns networks in given location sorted by VLAN ID
+func (s *Server) locationNetworks(loc string) []vpc.NetworkInfo {
+	nets := []vpc.NetworkInfo{}
+	for _, n := range s.networks {
+		if n.location == loc {
+			nets = append(nets, n.NetworkInfo)
+		}
+	}
+	sort.Slice(nets, func(i, j int) bool { return nets[i].VLANID < nets[j].VLANID })
+	return nets
+}
+
+// findNetwork returns network with given id in given location, writes 404 response if not found.
+func (s *Server) findNetwork(w http.ResponseWriter, loc, id string) (*network, bool) {
+	u, ok := parseUUID(w, id)
+	if !ok {
+		return nil, false
+	}
+	n, ok := s.networks[u]
+	if !ok || n.location != loc {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("network %s not found", id))
+		return nil, false
+	}
+	return n, true
+}
+
+func (s *Server) listNetworks(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	writeJSON(w, http.StatusOK, s.locationNetworks(p["loc"]))
+}
+
+func (s *Server) getNetwork(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	if n, ok := s.findNetwork(w, p["loc"], p["id"]); ok {
+		writeJSON(w, http.StatusOK, n.NetworkInfo)
+	}
+}
+
+// getOrCreateDefaultNetwork returns the default network of the location, creates it when there's none.
+func (s *Server) getOrCreateDefaultNetwork(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	for _, n := range s.networks {
+		if n.location == p["loc"] && n.IsDefault {
+			writeJSON(w, http.StatusOK, n.NetworkInfo)
+			return
+		}
+	}
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	n := s.newNetwork(p["loc"], name)
+	n.IsDefault = true
+	writeJSON(w, http.StatusOK, n.NetworkInfo)
+}
+
+func (s *Server) renameNetwork(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	n, ok := s.findNetwork(w, p["loc"], p["id"])
+	if !ok {
+		return
+	}
+	var payload struct {
+		Name string `json:"name"`
+	}
+	if !decodeJSON(w, r, &payload) {
+		return
+	}
+	if payload.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	n.Name = payload.Name
+	n.UpdatedAt = now()
+	writeJSON(w, http.StatusOK, n.NetworkInfo)
+}
+
+func (s *Server) deleteNetwork(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	n, ok := s.findNetwork(w, p["loc"], p["id"])
+	if !ok {
+		return
+	}
+	if n.IsDefault {
+		writeError(w, http.StatusConflict, "default network can not be deleted")
+		return
+	}
+	if len(n.VMUUIDs) > 0 {
+		writeError(w, http.StatusConflict, "network has resources")
+		return
+	}
+	delete(s.networks, n.UUID)
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+func (s *Server) setDefaultNetwork(w http.ResponseWriter, r *http.Request, p map[string]string) {
+	n, ok := s.findNetwork(w, p["loc"], p["id"])
+	if !ok {
+		return
+	}
+	for _, other := range s.networks {
+		if other.location == n.location {
+			other.IsDefault = false
+		}
+	}
+	n.IsDefault = true
+	n.UpdatedAt = now()
+	writeJSON(w, http.StatusOK, n.NetworkInfo)
+}
+
+// AddNetwork adds a non-default network to given location, useful to seed the server state.
+func (s *Server) AddNetwork(loc, name string) vpc.NetworkInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.newNetwork(loc, name).NetworkInfo
+}
+
+// defaultNetwork returns the default network of the location, creates it when there's none.
+func (s *Server) defaultNetwork(loc string) *network {
+	for _, n := range s.networks {
+		if n.location == loc && n.IsDefault {
+			return n
+		}
+	}
+	n := s.newNetwork(loc, "Default")
+	n.IsDefault = true
+	return n
+}
+
+// newNetwork creates and stores a new network
+func (s *Server) newNetwork(loc, name string) *network {
+	vlan := s.id()
+	n := &network{
+		location: loc,
+		NetworkInfo: vpc.NetworkInfo{
+			VLANID:     vlan,
+			UUID:       uuid.New(),
+			Name:       name,
+			Subnet:     fmt.Sprintf("10.%d.0.0/24", vlan%256),
+			SubnetIPV6: fmt.Sprintf("fd00:%x::/64", vlan),
+			Type:       "private",
+			VMUUIDs:    uuid.UUIDs{},
+			CreatedAt:  now(),
+			UpdatedAt:  now(),
+		},
+	}
+	s.networks[n.UUID] = n
+	return n
+}